@@ -2,6 +2,7 @@ package cfg
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -25,18 +26,54 @@ func (config DBConfig) GetConnectionString() string {
 	)
 }
 
+type StorageConfig struct {
+	Path           string
+	MaxUploadBytes int64
+}
+
+// Where the public site lives. Articles are read at ArticleBaseURL + uri,
+// which defaults to BaseURL + "/articles/". APIBaseURL is where this service
+// is reached, for links to things it serves such as social card images.
+type SiteConfig struct {
+	Name           string
+	BaseURL        string
+	ArticleBaseURL string
+	APIBaseURL     string
+	Robots         RobotsConfig
+}
+
+// Paths crawlers are asked to keep out of, or allowed into despite that
+type RobotsConfig struct {
+	Allow    []string
+	Disallow []string
+}
+
+func (config SiteConfig) ArticleURL(uri string) string {
+	return config.ArticleBaseURL + uri
+}
+
 type Config struct {
 	Database DBConfig
+	Storage  StorageConfig
+	Site     SiteConfig
 }
 
 func Load() *Config {
 	viper.SetConfigName("conf")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
+	viper.SetDefault("storage.path", "uploads")
+	viper.SetDefault("storage.max_upload_mb", 10)
 	err := viper.ReadInConfig()
 	if err != nil {
 		panic(fmt.Errorf("fatal error config file: %w", err))
 	}
+
+	siteBaseURL := strings.TrimSuffix(viper.GetString("site.base_url"), "/")
+	articleBaseURL := viper.GetString("site.article_base_url")
+	if articleBaseURL == "" && siteBaseURL != "" {
+		articleBaseURL = siteBaseURL + "/articles/"
+	}
 	return &Config{
 		Database: DBConfig{
 			User:     viper.GetString("postgresql.user"),
@@ -45,5 +82,19 @@ func Load() *Config {
 			Port:     viper.GetString("postgresql.port"),
 			Database: viper.GetString("postgresql.database"),
 		},
+		Storage: StorageConfig{
+			Path:           viper.GetString("storage.path"),
+			MaxUploadBytes: viper.GetInt64("storage.max_upload_mb") << 20,
+		},
+		Site: SiteConfig{
+			Name:           viper.GetString("site.name"),
+			BaseURL:        siteBaseURL,
+			ArticleBaseURL: articleBaseURL,
+			APIBaseURL:     strings.TrimSuffix(viper.GetString("site.api_base_url"), "/"),
+			Robots: RobotsConfig{
+				Allow:    viper.GetStringSlice("site.robots.allow"),
+				Disallow: viper.GetStringSlice("site.robots.disallow"),
+			},
+		},
 	}
 }
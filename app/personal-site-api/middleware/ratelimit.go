@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Allows each client a number of requests within a sliding window
+type RateLimiter struct {
+	Limit  int
+	Window time.Duration
+
+	mu       sync.Mutex
+	requests map[string][]time.Time
+	now      func() time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Limit:    limit,
+		Window:   window,
+		requests: map[string][]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Records a request for the key if it's within the limit, otherwise reports
+// how long until the oldest request in the window expires
+func (limiter *RateLimiter) Allow(key string) (bool, time.Duration) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	cutoff := now.Add(-limiter.Window)
+	recent := limiter.requests[key][:0]
+	for _, t := range limiter.requests[key] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= limiter.Limit {
+		limiter.requests[key] = recent
+		return false, recent[0].Sub(cutoff)
+	}
+	limiter.requests[key] = append(recent, now)
+
+	// drop clients that have gone quiet so the map doesn't grow forever
+	if len(limiter.requests) > 10000 {
+		for k, times := range limiter.requests {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(limiter.requests, k)
+			}
+		}
+	}
+	return true, 0
+}
+
+// Answers 429 with a Retry-After once a client address runs over the limit
+func RateLimitMiddleware(limiter *RateLimiter, next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := limiter.Allow(ClientAddress(r))
+		if !ok {
+			w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// The address the request came from, without its port
+func ClientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,147 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+)
+
+const (
+	defaultStatsDays  = 30
+	maxStatsDays      = 366
+	defaultStatsLimit = 10
+	maxStatsLimit     = 100
+)
+
+var botPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|preview|headless`)
+
+// What the page sends with a view. Referrer is the reader's document.referrer,
+// since the request itself always comes from our own page.
+type viewBody struct {
+	Referrer string `json:"referrer"`
+}
+
+// Counts a view of the article. Works with navigator.sendBeacon, so the body
+// is read as JSON whatever its content type, and may be empty. Answers 204
+// whether or not the view was counted.
+func RecordViewHandler(model AnalyticsDataAccessLayer, hasher *VisitorHasher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleURI := vars["articleURI"]
+
+		var body viewBody
+		raw, err := ioutil.ReadAll(io.LimitReader(r.Body, 4096))
+		if err != nil {
+			http.Error(w, "problem reading body", http.StatusBadRequest)
+			return
+		}
+		if len(strings.TrimSpace(string(raw))) > 0 {
+			err = json.Unmarshal(raw, &body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		userAgent := r.Header.Get("User-Agent")
+		if userAgent == "" || botPattern.MatchString(userAgent) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		now := time.Now().UTC()
+		day := now.Format(dateFormat)
+		visitor := hasher.Hash(day, middleware.ClientAddress(r), userAgent, articleURI)
+		_, err = model.RecordView(articleURI, now.Truncate(24*time.Hour), visitor, referrerHost(body.Referrer))
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem recording view", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Only the referring site is kept, never the page
+func referrerHost(referrer string) string {
+	u, err := url.Parse(referrer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// Views between ?from and ?to (inclusive dates, defaulting to the last 30
+// days), optionally for a single ?uri, with the top ?limit articles and referrers
+func GetStatsHandler(model AnalyticsDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseStatsQuery(r, time.Now().UTC())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stats, err := model.Stats(query)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "problem fetching stats", http.StatusInternalServerError)
+			return
+		}
+		jbytes, err := json.Marshal(stats)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+func parseStatsQuery(r *http.Request, now time.Time) (query StatsQuery, err error) {
+	values := r.URL.Query()
+	query = StatsQuery{
+		To:         now.Truncate(24 * time.Hour),
+		ArticleURI: values.Get("uri"),
+		Limit:      defaultStatsLimit,
+	}
+	if to := values.Get("to"); to != "" {
+		query.To, err = time.Parse(dateFormat, to)
+		if err != nil {
+			return query, fmt.Errorf("to must be a date like %s", dateFormat)
+		}
+	}
+	query.From = query.To.AddDate(0, 0, 1-defaultStatsDays)
+	if from := values.Get("from"); from != "" {
+		query.From, err = time.Parse(dateFormat, from)
+		if err != nil {
+			return query, fmt.Errorf("from must be a date like %s", dateFormat)
+		}
+	}
+	if query.From.After(query.To) {
+		return query, fmt.Errorf("from must not be after to")
+	}
+	if query.To.Sub(query.From) >= maxStatsDays*24*time.Hour {
+		return query, fmt.Errorf("the range can be at most %d days", maxStatsDays)
+	}
+	if limit := values.Get("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > maxStatsLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxStatsLimit)
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,185 @@
+package analytics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type MockAnalyticsModel struct {
+	articles  []string
+	visitors  map[string]bool
+	views     map[string]int
+	referrers map[string]int
+	query     *StatsQuery
+}
+
+func (model MockAnalyticsModel) RecordView(articleURI string, day time.Time, visitorHash string, referrer string) (bool, error) {
+	found := false
+	for _, article := range model.articles {
+		found = found || article == articleURI
+	}
+	if !found {
+		return false, errors.New("no rows in result set")
+	}
+	if model.visitors[visitorHash] {
+		return false, nil
+	}
+	model.visitors[visitorHash] = true
+	model.views[articleURI]++
+	if referrer != "" {
+		model.referrers[referrer]++
+	}
+	return true, nil
+}
+func (model MockAnalyticsModel) Stats(query StatsQuery) (Stats, error) {
+	*model.query = query
+	return Stats{}, nil
+}
+
+func newMockModel() MockAnalyticsModel {
+	return MockAnalyticsModel{
+		articles:  []string{"some-article-1", "some-article-2"},
+		visitors:  map[string]bool{},
+		views:     map[string]int{},
+		referrers: map[string]int{},
+		query:     &StatsQuery{},
+	}
+}
+
+func TestRecordViewHandler(t *testing.T) {
+	model := newMockModel()
+	handler := RecordViewHandler(model, &VisitorHasher{})
+
+	cases := []struct {
+		articleURI   string
+		remoteAddr   string
+		userAgent    string
+		body         string
+		expectedCode int
+	}{
+		{"some-article-1", "203.0.113.7:5555", "Firefox", `{"referrer": "https://www.Example.org/a/page?q=1"}`, 204},
+		// same reader again from a different port, not counted twice
+		{"some-article-1", "203.0.113.7:6666", "Firefox", ``, 204},
+		{"some-article-2", "203.0.113.7:5555", "Firefox", ``, 204},
+		{"some-article-1", "203.0.113.8:5555", "Firefox", `{"referrer": "android-app://com.example"}`, 204},
+		{"some-article-1", "203.0.113.9:5555", "Googlebot/2.1", ``, 204},
+		{"some-article-1", "203.0.113.9:5555", "", ``, 204},
+		{"some-article-1", "203.0.113.9:5555", "Firefox", `{"referrer": `, 422},
+		{"some-article-3", "203.0.113.9:5555", "Firefox", ``, 404},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("POST", "/api/v1/articles/"+c.articleURI+"/views", bytes.NewBufferString(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = c.remoteAddr
+		req.Header.Set("User-Agent", c.userAgent)
+		req = mux.SetURLVars(req, map[string]string{"articleURI": c.articleURI})
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %+v but received %d", c.expectedCode, c, rr.Code)
+		}
+	}
+
+	expectedViews := map[string]int{"some-article-1": 2, "some-article-2": 1}
+	for uri, expected := range expectedViews {
+		if model.views[uri] != expected {
+			t.Errorf("expected %d views of %s but received %d", expected, uri, model.views[uri])
+		}
+	}
+	if len(model.referrers) != 1 || model.referrers["example.org"] != 1 {
+		t.Errorf("expected a single view referred by example.org but received %v", model.referrers)
+	}
+}
+
+func TestVisitorHasher(t *testing.T) {
+	hasher := &VisitorHasher{}
+	first := hasher.Hash("2022-01-02", "203.0.113.7", "Firefox")
+	if hasher.Hash("2022-01-02", "203.0.113.7", "Firefox") != first {
+		t.Errorf("expected the same visitor to hash the same within a day")
+	}
+	if hasher.Hash("2022-01-02", "203.0.113.7", "Chrome") == first {
+		t.Errorf("expected different visitors to hash differently")
+	}
+	hasher.Hash("2022-01-03", "203.0.113.7", "Firefox")
+	if hasher.Hash("2022-01-02", "203.0.113.7", "Firefox") == first {
+		t.Errorf("expected the salt to be replaced with the day")
+	}
+}
+
+func TestParseStatsQuery(t *testing.T) {
+	now := time.Date(2022, 3, 15, 13, 0, 0, 0, time.UTC)
+	cases := []struct {
+		query     string
+		from      string
+		to        string
+		limit     int
+		expectErr bool
+	}{
+		{"", "2022-02-14", "2022-03-15", 10, false},
+		{"?from=2022-01-01&to=2022-01-31&limit=5", "2022-01-01", "2022-01-31", 5, false},
+		{"?to=2022-01-31", "2022-01-02", "2022-01-31", 10, false},
+		{"?from=2022-02-01&to=2022-01-31", "", "", 0, true},
+		{"?from=2020-01-01&to=2022-01-31", "", "", 0, true},
+		{"?from=yesterday", "", "", 0, true},
+		{"?limit=0", "", "", 0, true},
+		{"?limit=101", "", "", 0, true},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", "/api/v1/articles/stats"+c.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query, err := parseStatsQuery(req, now)
+		if (err != nil) != c.expectErr {
+			t.Errorf("expected error to be %t for '%s' but received %v", c.expectErr, c.query, err)
+			continue
+		}
+		if c.expectErr {
+			continue
+		}
+		if from := query.From.Format(dateFormat); from != c.from {
+			t.Errorf("expected from %s for '%s' but received %s", c.from, c.query, from)
+		}
+		if to := query.To.Format(dateFormat); to != c.to {
+			t.Errorf("expected to %s for '%s' but received %s", c.to, c.query, to)
+		}
+		if query.Limit != c.limit {
+			t.Errorf("expected limit %d for '%s' but received %d", c.limit, c.query, query.Limit)
+		}
+	}
+}
+
+func TestGetStatsHandler(t *testing.T) {
+	model := newMockModel()
+	req, err := http.NewRequest("GET", "/api/v1/articles/stats?uri=some-article-1&from=2022-01-01&to=2022-01-31", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	GetStatsHandler(model).ServeHTTP(rr, req)
+	if rr.Code != 200 {
+		t.Errorf("expected status code %d but received %d", 200, rr.Code)
+	}
+	if model.query.ArticleURI != "some-article-1" {
+		t.Errorf("expected stats for 'some-article-1' but received '%s'", model.query.ArticleURI)
+	}
+
+	req, err = http.NewRequest("GET", "/api/v1/articles/stats?from=nope", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	GetStatsHandler(model).ServeHTTP(rr, req)
+	if rr.Code != 400 {
+		t.Errorf("expected status code %d but received %d", 400, rr.Code)
+	}
+}
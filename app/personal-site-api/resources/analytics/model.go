@@ -0,0 +1,211 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const dateFormat = "2006-01-02"
+
+// Which articles were read over a range of days, where readers came from and
+// how views moved from day to day
+type Stats struct {
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	TotalViews  int             `json:"totalViews"`
+	TopArticles []ArticleViews  `json:"topArticles"`
+	Referrers   []ReferrerViews `json:"referrers"`
+	Daily       []DailyViews    `json:"daily"`
+}
+
+type ArticleViews struct {
+	URI   string `json:"uri"`
+	Title string `json:"title"`
+	Views int    `json:"views"`
+}
+
+type ReferrerViews struct {
+	Referrer string `json:"referrer"`
+	Views    int    `json:"views"`
+}
+
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"`
+}
+
+// The days to report on, inclusive, optionally narrowed to one article
+type StatsQuery struct {
+	From       time.Time
+	To         time.Time
+	ArticleURI string
+	Limit      int
+}
+
+// An interface to refresent the Model (for mocking in test)
+type AnalyticsDataAccessLayer interface {
+	RecordView(articleURI string, day time.Time, visitorHash string, referrer string) (counted bool, err error)
+	Stats(query StatsQuery) (stats Stats, err error)
+}
+
+// The Model with Database Implementation
+type AnalyticsModel struct {
+	DB *pgx.Conn
+}
+
+// Counts a view unless the visitor has already viewed the article that day.
+// Only the current day's visitor hashes are kept, older ones are cleared out.
+func (model *AnalyticsModel) RecordView(articleURI string, day time.Time, visitorHash string, referrer string) (counted bool, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var articleID int
+	err = tx.QueryRow(ctx, "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM article_view_visitors WHERE day < $1;", day)
+	if err != nil {
+		return false, err
+	}
+	tag, err := tx.Exec(
+		ctx,
+		"INSERT INTO article_view_visitors (day, visitor_hash) VALUES ($1, $2) ON CONFLICT DO NOTHING;",
+		day, visitorHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, tx.Commit(ctx)
+	}
+
+	stmt := `
+		INSERT INTO article_view_counts (article_id, day, views)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (article_id, day)
+		DO
+			UPDATE SET views = article_view_counts.views + 1
+	`
+	_, err = tx.Exec(ctx, stmt, articleID, day)
+	if err != nil {
+		return false, err
+	}
+	if referrer != "" {
+		stmt = `
+			INSERT INTO article_view_referrers (article_id, day, referrer, views)
+			VALUES ($1, $2, $3, 1)
+			ON CONFLICT (article_id, day, referrer)
+			DO
+				UPDATE SET views = article_view_referrers.views + 1
+		`
+		_, err = tx.Exec(ctx, stmt, articleID, day, referrer)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (model *AnalyticsModel) Stats(query StatsQuery) (stats Stats, err error) {
+	ctx := context.Background()
+	stats = Stats{
+		From:        query.From.Format(dateFormat),
+		To:          query.To.Format(dateFormat),
+		TopArticles: []ArticleViews{},
+		Referrers:   []ReferrerViews{},
+		Daily:       []DailyViews{},
+	}
+	args := []interface{}{query.From, query.To, query.ArticleURI}
+
+	stmt := `
+		SELECT articles.uri, articles.title, SUM(views)
+		FROM article_view_counts
+		JOIN articles ON articles.id = article_view_counts.article_id
+		WHERE day BETWEEN $1 AND $2 AND ($3 = '' OR articles.uri = $3)
+		GROUP BY articles.uri, articles.title
+		ORDER BY SUM(views) DESC, articles.uri
+		LIMIT $4;
+	`
+	rows, err := model.DB.Query(ctx, stmt, append(args, query.Limit)...)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var a ArticleViews
+		err = rows.Scan(&a.URI, &a.Title, &a.Views)
+		if err != nil {
+			rows.Close()
+			return stats, err
+		}
+		stats.TopArticles = append(stats.TopArticles, a)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return stats, rows.Err()
+	}
+
+	stmt = `
+		SELECT referrer, SUM(views)
+		FROM article_view_referrers
+		JOIN articles ON articles.id = article_view_referrers.article_id
+		WHERE day BETWEEN $1 AND $2 AND ($3 = '' OR articles.uri = $3)
+		GROUP BY referrer
+		ORDER BY SUM(views) DESC, referrer
+		LIMIT $4;
+	`
+	rows, err = model.DB.Query(ctx, stmt, append(args, query.Limit)...)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var r ReferrerViews
+		err = rows.Scan(&r.Referrer, &r.Views)
+		if err != nil {
+			rows.Close()
+			return stats, err
+		}
+		stats.Referrers = append(stats.Referrers, r)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return stats, rows.Err()
+	}
+
+	// every day in the range, including ones nobody visited
+	stmt = `
+		SELECT days.day::date, COALESCE(SUM(counts.views), 0)
+		FROM generate_series($1::date, $2::date, interval '1 day') AS days(day)
+		LEFT JOIN (
+			SELECT day, views
+			FROM article_view_counts
+			JOIN articles ON articles.id = article_view_counts.article_id
+			WHERE $3 = '' OR articles.uri = $3
+		) AS counts ON counts.day = days.day::date
+		GROUP BY days.day
+		ORDER BY days.day;
+	`
+	rows, err = model.DB.Query(ctx, stmt, args...)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day time.Time
+		var views int
+		err = rows.Scan(&day, &views)
+		if err != nil {
+			return stats, err
+		}
+		stats.Daily = append(stats.Daily, DailyViews{Date: day.Format(dateFormat), Views: views})
+		stats.TotalViews += views
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,14 @@
+package analytics
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+)
+
+// Registered on the articles router ahead of the article routes, so /stats
+// isn't taken for an article uri
+func InitializeRoutes(router *mux.Router, model AnalyticsDataAccessLayer) {
+	hasher := &VisitorHasher{}
+	router.HandleFunc("/stats", middleware.AuthMiddleware(GetStatsHandler(model))).Methods("GET")
+	router.HandleFunc("/{articleURI}/views", RecordViewHandler(model, hasher)).Methods("POST")
+}
@@ -0,0 +1,36 @@
+package analytics
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// Hashes visitors with a random salt that's replaced every day and only ever
+// held in memory, so a hash can't be tied back to an address or matched up
+// across days
+type VisitorHasher struct {
+	mu   sync.Mutex
+	day  string
+	salt []byte
+}
+
+func (hasher *VisitorHasher) Hash(day string, parts ...string) string {
+	hasher.mu.Lock()
+	if hasher.day != day {
+		hasher.salt = make([]byte, 32)
+		if _, err := rand.Read(hasher.salt); err != nil {
+			panic(err)
+		}
+		hasher.day = day
+	}
+	salt := hasher.salt
+	hasher.mu.Unlock()
+
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h.Sum(nil))
+}
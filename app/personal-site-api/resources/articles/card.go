@@ -0,0 +1,210 @@
+package articles
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	cardBackground = color.RGBA{0x1b, 0x1f, 0x2a, 0xff}
+	cardAccent     = color.RGBA{0x4f, 0xb3, 0xbf, 0xff}
+	cardTitle      = color.RGBA{0xf5, 0xf6, 0xf8, 0xff}
+	cardSummary    = color.RGBA{0xb8, 0xbe, 0xcc, 0xff}
+)
+
+const cardMargin = 80
+
+// The bundled Go fonts, parsed once. Faces aren't safe to share between
+// goroutines, so each render makes its own.
+var (
+	cardFontsOnce         sync.Once
+	cardBold, cardRegular *opentype.Font
+	cardFontsErr          error
+)
+
+func loadCardFonts() error {
+	cardFontsOnce.Do(func() {
+		cardBold, cardFontsErr = opentype.Parse(gobold.TTF)
+		if cardFontsErr == nil {
+			cardRegular, cardFontsErr = opentype.Parse(goregular.TTF)
+		}
+	})
+	return cardFontsErr
+}
+
+// Draws the article's social card: title, summary and the site name along
+// the bottom with the reading time
+func renderCard(a Article, siteName string) ([]byte, error) {
+	err := loadCardFonts()
+	if err != nil {
+		return nil, err
+	}
+	titleFace, err := opentype.NewFace(cardBold, &opentype.FaceOptions{Size: 64, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	summaryFace, err := opentype.NewFace(cardRegular, &opentype.FaceOptions{Size: 34, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer summaryFace.Close()
+	brandFace, err := opentype.NewFace(cardBold, &opentype.FaceOptions{Size: 30, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer brandFace.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(cardBackground), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, CardWidth, 16), image.NewUniform(cardAccent), image.Point{}, draw.Src)
+
+	textWidth := CardWidth - 2*cardMargin
+	y := cardMargin + 40
+	for _, line := range wrapText(titleFace, a.Title, textWidth, 3) {
+		y += 64
+		drawText(img, titleFace, cardTitle, cardMargin, y, line)
+		y += 12
+	}
+	// the summary gets whatever room the title leaves above the footer
+	y += 24
+	footerTop := CardHeight - cardMargin - 70
+	summaryLines := (footerTop - y) / 48
+	if summaryLines > 4 {
+		summaryLines = 4
+	}
+	if summaryLines > 0 {
+		for _, line := range wrapText(summaryFace, a.Summary, textWidth, summaryLines) {
+			y += 34
+			drawText(img, summaryFace, cardSummary, cardMargin, y, line)
+			y += 14
+		}
+	}
+
+	footer := siteName
+	if a.ReadingMinutes > 0 {
+		reading := fmt.Sprintf("%d min read", a.ReadingMinutes)
+		if footer != "" {
+			footer += "  ·  "
+		}
+		footer += reading
+	}
+	draw.Draw(img, image.Rect(cardMargin, CardHeight-cardMargin-50, cardMargin+60, CardHeight-cardMargin-44), image.NewUniform(cardAccent), image.Point{}, draw.Src)
+	drawText(img, brandFace, cardAccent, cardMargin, CardHeight-cardMargin, footer)
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	return buf.Bytes(), err
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, x int, y int, text string) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+// Breaks text into lines that fit the width, ending the last line with an
+// ellipsis if the text runs past maxLines. A word too long for a line of its
+// own is cut to fit.
+func wrapText(face font.Face, text string, width int, maxLines int) []string {
+	limit := fixed.I(width)
+	fits := func(s string) bool { return font.MeasureString(face, s) <= limit }
+
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if fits(candidate) {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		current = word
+		for !fits(current) && len([]rune(current)) > 1 {
+			cut := []rune(current)
+			n := len(cut) - 1
+			for n > 1 && !fits(string(cut[:n])) {
+				n--
+			}
+			lines = append(lines, string(cut[:n]))
+			current = string(cut[n:])
+		}
+		if len(lines) >= maxLines {
+			return ellipsize(face, lines[:maxLines], limit)
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// Trims the last line until it fits with an ellipsis on the end
+func ellipsize(face font.Face, lines []string, limit fixed.Int26_6) []string {
+	last := []rune(lines[len(lines)-1])
+	for len(last) > 0 && font.MeasureString(face, strings.TrimSpace(string(last))+"…") > limit {
+		last = last[:len(last)-1]
+	}
+	lines[len(lines)-1] = strings.TrimSpace(string(last)) + "…"
+	return lines
+}
+
+// Rendered cards by uri, each kept until the article changes. Old entries are
+// dropped at random once the cache is full.
+type cardCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]cachedCard
+}
+
+type cachedCard struct {
+	version string
+	png     []byte
+}
+
+func newCardCache(size int) *cardCache {
+	return &cardCache{size: size, entries: map[string]cachedCard{}}
+}
+
+func (cache *cardCache) get(uri string, version string) ([]byte, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.entries[uri]
+	if !ok || entry.version != version {
+		return nil, false
+	}
+	return entry.png, true
+}
+
+func (cache *cardCache) put(uri string, version string, png []byte) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if _, ok := cache.entries[uri]; !ok && len(cache.entries) >= cache.size {
+		for key := range cache.entries {
+			delete(cache.entries, key)
+			break
+		}
+	}
+	cache.entries[uri] = cachedCard{version: version, png: png}
+}
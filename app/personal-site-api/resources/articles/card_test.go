@@ -0,0 +1,155 @@
+package articles
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var testSite = cfg.SiteConfig{
+	Name:           "Some Site",
+	BaseURL:        "https://example.com",
+	ArticleBaseURL: "https://example.com/articles/",
+	APIBaseURL:     "https://api.example.com",
+}
+
+func testFace(t *testing.T) font.Face {
+	err := loadCardFonts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(cardRegular, &opentype.FaceOptions{Size: 34, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return face
+}
+
+func TestWrapText(t *testing.T) {
+	face := testFace(t)
+	defer face.Close()
+	width := 300
+
+	lines := wrapText(face, "short", width, 3)
+	if len(lines) != 1 || lines[0] != "short" {
+		t.Errorf("expected a single line but received %q", lines)
+	}
+
+	long := strings.Repeat("some words that keep going ", 20)
+	lines = wrapText(face, long, width, 3)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines but received %d", len(lines))
+	}
+	if !strings.HasSuffix(lines[2], "…") {
+		t.Errorf("expected the last line to be ellipsized but received %q", lines[2])
+	}
+	for _, line := range lines {
+		if font.MeasureString(face, line) > fixed.I(width) {
+			t.Errorf("expected %q to fit within %dpx", line, width)
+		}
+	}
+
+	lines = wrapText(face, strings.Repeat("a", 200), width, 10)
+	if len(lines) < 2 {
+		t.Errorf("expected an overlong word to be broken up but received %q", lines)
+	}
+}
+
+func TestSocialMeta(t *testing.T) {
+	a := Article{
+		URI:         "some-article-1",
+		Title:       "Some Article",
+		Summary:     "A Short Summary",
+		DateCreated: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	if meta := socialMeta(a, cfg.SiteConfig{}); meta != nil {
+		t.Errorf("expected no meta without a configured site but received %+v", meta)
+	}
+
+	meta := socialMeta(a, testSite)
+	expectedImage := "https://api.example.com/api/v1/articles/some-article-1/og.png?v=1641092645"
+	expected := map[string]string{
+		"og:title":     "Some Article",
+		"og:url":       "https://example.com/articles/some-article-1",
+		"og:site_name": "Some Site",
+		"og:image":     expectedImage,
+	}
+	for key, value := range expected {
+		if meta.OpenGraph[key] != value {
+			t.Errorf("expected %s to be '%s' but received '%s'", key, value, meta.OpenGraph[key])
+		}
+	}
+	if meta.Twitter["twitter:card"] != "summary_large_image" || meta.Twitter["twitter:image"] != expectedImage {
+		t.Errorf("expected a large image twitter card but received %v", meta.Twitter)
+	}
+
+	noImages := testSite
+	noImages.APIBaseURL = ""
+	meta = socialMeta(a, noImages)
+	if _, ok := meta.OpenGraph["og:image"]; ok || meta.Twitter["twitter:card"] != "summary" {
+		t.Errorf("expected no card image without an api base url but received %+v", meta)
+	}
+}
+
+func TestGetArticleCardHandler(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:              1,
+				URI:             "some-article-1",
+				Title:           "Some Article With A Title Long Enough To Need Wrapping Onto More Than One Line",
+				Summary:         "A Short Summary",
+				ArticleMetadata: ArticleMetadata{ReadingMinutes: 4},
+				DateCreated:     time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		},
+	}
+	handler := GetArticleCardHandler(model, testSite)
+
+	serve := func(uri string, etag string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/api/v1/articles/"+uri+"/og.png", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"articleURI": uri}))
+		return rr
+	}
+
+	rr := serve("some-article-1", "")
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d", 200, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "image/png" {
+		t.Errorf("expected a png but received '%s'", contentType)
+	}
+	img, err := png.Decode(bytes.NewReader(rr.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != CardWidth || img.Bounds().Dy() != CardHeight {
+		t.Errorf("expected a %dx%d card but received %v", CardWidth, CardHeight, img.Bounds())
+	}
+
+	etag := rr.Header().Get("ETag")
+	if rr := serve("some-article-1", etag); rr.Code != 304 {
+		t.Errorf("expected status code %d but received %d", 304, rr.Code)
+	}
+	if rr := serve("missing-article", ""); rr.Code != 404 {
+		t.Errorf("expected status code %d but received %d", 404, rr.Code)
+	}
+}
@@ -1,28 +1,122 @@
 package articles
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
 	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
 )
 
 func GetArticlesHandler(model ArticleDataAccessLayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		articles, err := model.All()
+		params, err := webserverutils.ParsePageParams(r, ArticlePageOptions)
 		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter := ArticleFilter{Tags: r.URL.Query()["tag"]}
+		page, err := model.List(filter, params)
+		if err != nil {
+			// a cursor that decodes but doesn't fit the sort is the client's doing
+			if strings.Contains(err.Error(), "Invalid Request Body:") {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			fmt.Println(err.Error())
 			http.Error(w, "problem fetching articles", http.StatusInternalServerError)
 			return
 		}
+		if page.Articles == nil {
+			page.Articles = []Article{}
+		}
+		articles, err := webserverutils.SelectFields(page.Articles, params.Fields)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
 		jbytes, err := json.Marshal(articles)
 		if err != nil {
 			http.Error(w, "internal error building response", http.StatusInternalServerError)
 			return
 		}
+		webserverutils.SetPageHeaders(w, r, page.Total, page.NextCursor)
+		lastModified := time.Time{}
+		for _, article := range page.Articles {
+			if article.LastModified().After(lastModified) {
+				lastModified = article.LastModified()
+			}
+		}
+		if webserverutils.CheckNotModified(w, r, webserverutils.ETag(jbytes), lastModified) {
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+func GetTagsHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := model.Tags()
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "problem fetching tags", http.StatusInternalServerError)
+			return
+		}
+		if tags == nil {
+			tags = []TagCount{}
+		}
+		jbytes, err := json.Marshal(tags)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+func SearchArticlesHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			http.Error(w, "request missing search query", http.StatusBadRequest)
+			return
+		}
+		params, err := webserverutils.ParsePageParams(r, SearchPageOptions)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err := model.Search(query, params)
+		if err != nil {
+			if strings.Contains(err.Error(), "Invalid Request Body:") {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem searching articles", http.StatusInternalServerError)
+			}
+			return
+		}
+		if page.Results == nil {
+			page.Results = []SearchResult{}
+		}
+		jbytes, err := json.Marshal(page.Results)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		webserverutils.SetPageHeaders(w, r, page.Total, page.NextCursor)
 		w.Header().Add("Content-Type", "application/json")
 		w.Write(jbytes)
 	}
@@ -39,7 +133,7 @@ func GetArticleHandler(model ArticleDataAccessLayer) http.HandlerFunc {
 		article, err := model.Get(articleURI)
 		if err != nil {
 			if err.Error() == "no rows in result set" {
-				http.Error(w, "article not found", http.StatusNotFound)
+				redirectToRenamedArticle(w, r, model, articleURI)
 			} else {
 				http.Error(w, "problem fetching article", http.StatusInternalServerError)
 			}
@@ -50,6 +144,106 @@ func GetArticleHandler(model ArticleDataAccessLayer) http.HandlerFunc {
 			http.Error(w, "internal error building response", http.StatusInternalServerError)
 			return
 		}
+		if webserverutils.CheckNotModified(w, r, webserverutils.ETag(jbytes), article.LastModified()) {
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+// A PNG preview card for link unfurls. Cards are cached by what's drawn on
+// them, so they're only rendered again once the article changes.
+func GetArticleCardHandler(model ArticleDataAccessLayer, site cfg.SiteConfig) http.HandlerFunc {
+	cache := newCardCache(200)
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleURI := vars["articleURI"]
+		article, err := model.Get(articleURI)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "problem fetching article", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		etag := webserverutils.ETag([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", article.Title, article.Summary, site.Name, article.ReadingMinutes)))
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		if webserverutils.CheckNotModified(w, r, etag, article.LastModified()) {
+			return
+		}
+		card, ok := cache.get(articleURI, etag)
+		if !ok {
+			card, err = renderCard(article, site.Name)
+			if err != nil {
+				fmt.Println(err.Error())
+				http.Error(w, "problem drawing card", http.StatusInternalServerError)
+				return
+			}
+			cache.put(articleURI, etag, card)
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(card)
+	}
+}
+
+// Sends readers of an old URI on to where the article lives now, or 404s
+func redirectToRenamedArticle(w http.ResponseWriter, r *http.Request, model ArticleDataAccessLayer, articleURI string) {
+	newURI, err := model.ResolveRedirect(articleURI)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			http.Error(w, "article not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "problem fetching article", http.StatusInternalServerError)
+		}
+		return
+	}
+	// a redirect back to itself would send clients round in circles
+	if newURI == articleURI {
+		fmt.Printf("redirect loop detected for article '%s'\n", articleURI)
+		http.Error(w, "article not found", http.StatusNotFound)
+		return
+	}
+	location := *r.URL
+	location.Path = strings.TrimSuffix(r.URL.Path, articleURI) + newURI
+	location.RawPath = ""
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+}
+
+func GetRelatedArticlesHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleURI := vars["articleURI"]
+
+		limit := DefaultRelatedLimit
+		if l := r.URL.Query().Get("limit"); l != "" {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil {
+				http.Error(w, "limit must be an integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		related, err := model.Related(articleURI, limit)
+		if err != nil {
+			if strings.Contains(err.Error(), "Invalid Request Body:") {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem fetching related articles", http.StatusInternalServerError)
+			}
+			return
+		}
+		jbytes, err := json.Marshal(related)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
 		w.Header().Add("Content-Type", "application/json")
 		w.Write(jbytes)
 	}
@@ -123,7 +317,214 @@ func UpdateArticleHandler(model ArticleDataAccessLayer) http.HandlerFunc {
 			return
 		}
 
-		updatedArticle, err := model.Update(articleURI, a)
+		current, ok := fetchArticleForEdit(w, r, model, articleURI)
+		if !ok {
+			return
+		}
+
+		updatedArticle, err := model.Update(articleURI, a, current.DateWritten())
+		if err != nil {
+			respondWithEditError(w, err)
+			return
+		}
+		respondWithEditedArticle(w, updatedArticle)
+	}
+}
+
+func PatchArticleHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleURI := vars["articleURI"]
+		if articleURI == "" {
+			http.Error(w, "request missing article ID", http.StatusNotFound)
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != webserverutils.MergePatchContentType && mediaType != webserverutils.JSONPatchContentType {
+			w.Header().Set("Accept-Patch", webserverutils.MergePatchContentType+", "+webserverutils.JSONPatchContentType)
+			http.Error(w, "unsupported patch format", http.StatusUnsupportedMediaType)
+			return
+		}
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "problem reading request body", http.StatusBadRequest)
+			return
+		}
+
+		current, ok := fetchArticleForEdit(w, r, model, articleURI)
+		if !ok {
+			return
+		}
+		currentBytes, err := json.Marshal(current)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+
+		var patchedBytes []byte
+		if mediaType == webserverutils.MergePatchContentType {
+			patchedBytes, err = webserverutils.MergePatch(currentBytes, patch)
+		} else {
+			patchedBytes, err = webserverutils.JSONPatch(currentBytes, patch)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		var patched Article
+		decoder := json.NewDecoder(bytes.NewReader(patchedBytes))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&patched)
+		if err != nil {
+			http.Error(w, webserverutils.NewRequestError(err.Error()).Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		errs := readOnlyFieldErrors(current, patched)
+		errs = append(errs, model.Validate(patched)...)
+		if len(errs) > 0 {
+			errMsgs := []string{}
+			for _, err := range errs {
+				errMsgs = append(errMsgs, err.Error())
+			}
+			msg := webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		fields := changedFields(current, patched)
+		if len(fields) == 0 {
+			respondWithEditedArticle(w, current)
+			return
+		}
+		updatedArticle, err := model.Patch(articleURI, patched, fields, current.DateWritten())
+		if err != nil {
+			respondWithEditError(w, err)
+			return
+		}
+		respondWithEditedArticle(w, updatedArticle)
+	}
+}
+
+// Loads the article being edited and checks the editor has seen its current
+// version. Writes the error response and returns false if they haven't.
+func fetchArticleForEdit(w http.ResponseWriter, r *http.Request, model ArticleDataAccessLayer, articleURI string) (Article, bool) {
+	current, err := model.Get(articleURI)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			http.Error(w, "article not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "problem fetching article", http.StatusInternalServerError)
+		}
+		return current, false
+	}
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		http.Error(w, "request missing If-Match header", http.StatusPreconditionRequired)
+		return current, false
+	}
+	currentBytes, err := json.Marshal(current)
+	if err != nil {
+		http.Error(w, "internal error building response", http.StatusInternalServerError)
+		return current, false
+	}
+	if !webserverutils.MatchesETag(ifMatch, webserverutils.ETag(currentBytes), false) {
+		http.Error(w, ErrPreconditionFailed.Error(), http.StatusPreconditionFailed)
+		return current, false
+	}
+	return current, true
+}
+
+func respondWithEditError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "Invalid Request Body:") {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	} else if err == ErrPreconditionFailed {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	} else if err.Error() == "no rows in result set" {
+		http.Error(w, "article not found", http.StatusNotFound)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func respondWithEditedArticle(w http.ResponseWriter, a Article) {
+	jbytes, _ := json.Marshal(a)
+	w.Header().Set("ETag", webserverutils.ETag(jbytes))
+	w.Header().Set("Last-Modified", a.LastModified().UTC().Format(http.TimeFormat))
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(jbytes)
+}
+
+// Patches may only touch editable fields; the uri changes through rename
+func readOnlyFieldErrors(current Article, patched Article) (errs []error) {
+	if patched.ID != current.ID {
+		errs = append(errs, errors.New("id cannot be changed"))
+	}
+	if patched.URI != current.URI {
+		errs = append(errs, errors.New("uri cannot be patched, use rename to change it"))
+	}
+	if !patched.DateCreated.Equal(current.DateCreated) {
+		errs = append(errs, errors.New("dateCreated cannot be changed"))
+	}
+	if (patched.DateUpdated == nil) != (current.DateUpdated == nil) ||
+		(patched.DateUpdated != nil && !patched.DateUpdated.Equal(*current.DateUpdated)) {
+		errs = append(errs, errors.New("dateUpdated cannot be changed"))
+	}
+	if !reflect.DeepEqual(patched.ArticleMetadata, current.ArticleMetadata) {
+		errs = append(errs, errors.New("wordCount, readingMinutes, outline and firstImage are derived from the body"))
+	}
+	if !reflect.DeepEqual(patched.Series, current.Series) || !reflect.DeepEqual(patched.Previous, current.Previous) ||
+		!reflect.DeepEqual(patched.Next, current.Next) {
+		errs = append(errs, errors.New("series navigation cannot be patched, edit the series instead"))
+	}
+	if !reflect.DeepEqual(patched.Social, current.Social) {
+		errs = append(errs, errors.New("social meta is derived from the article"))
+	}
+	return errs
+}
+
+// The editable fields that differ between two versions of an article
+func changedFields(current Article, patched Article) (fields []string) {
+	if patched.Title != current.Title {
+		fields = append(fields, "title")
+	}
+	if patched.Summary != current.Summary {
+		fields = append(fields, "summary")
+	}
+	if patched.Body != current.Body {
+		fields = append(fields, "body")
+	}
+	if strings.Join(patched.Tags, ",") != strings.Join(current.Tags, ",") {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+func RenameArticleHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	type RenameArticleReqBody struct {
+		URI string `json:"uri"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleURI := vars["articleURI"]
+		if articleURI == "" {
+			http.Error(w, "request missing article ID", http.StatusNotFound)
+			return
+		}
+
+		var reqBody RenameArticleReqBody
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&reqBody)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		renamedArticle, err := model.Rename(articleURI, reqBody.URI)
 		if err != nil {
 			if strings.Contains(err.Error(), "Invalid Request Body:") {
 				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
@@ -135,8 +536,111 @@ func UpdateArticleHandler(model ArticleDataAccessLayer) http.HandlerFunc {
 			return
 		}
 
-		bytes, _ := json.Marshal(updatedArticle)
+		bytes, _ := json.Marshal(renamedArticle)
+		location := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/rename"), articleURI) + renamedArticle.URI
+		w.Header().Set("Location", location)
+		w.Header().Set("ETag", webserverutils.ETag(bytes))
 		w.Header().Add("Content-Type", "application/json")
 		w.Write(bytes)
 	}
 }
+
+func GetSeriesHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		seriesSlug := vars["seriesSlug"]
+		series, err := model.GetSeries(seriesSlug)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "series not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem fetching series", http.StatusInternalServerError)
+			}
+			return
+		}
+		jbytes, err := json.Marshal(series)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+func CreateSeriesHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		series, ok := decodeSeries(w, r, model)
+		if !ok {
+			return
+		}
+
+		savedSeries, err := model.SaveSeries(series)
+		if err != nil {
+			if strings.Contains(err.Error(), "Invalid Request Body:") {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		bytes, _ := json.Marshal(savedSeries)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(bytes)
+	}
+}
+
+func UpdateSeriesHandler(model ArticleDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		seriesSlug := vars["seriesSlug"]
+
+		series, ok := decodeSeries(w, r, model)
+		if !ok {
+			return
+		}
+
+		updatedSeries, err := model.UpdateSeries(seriesSlug, series)
+		if err != nil {
+			if strings.Contains(err.Error(), "Invalid Request Body:") {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			} else if err.Error() == "no rows in result set" {
+				http.Error(w, "series not found", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		bytes, _ := json.Marshal(updatedSeries)
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(bytes)
+	}
+}
+
+// Reads and validates a series from the request body, writing the error response if it's invalid
+func decodeSeries(w http.ResponseWriter, r *http.Request, model ArticleDataAccessLayer) (Series, bool) {
+	var series Series
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&series)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return series, false
+	}
+
+	errs := model.ValidateSeries(series)
+	if len(errs) > 0 {
+		errMsgs := []string{}
+		for _, err := range errs {
+			errMsgs = append(errMsgs, err.Error())
+		}
+		msg := webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+		http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+		return series, false
+	}
+	return series, true
+}
@@ -21,9 +21,72 @@ type MockArticleModel struct {
 	fetchError       error
 	updateError      error
 	saveError        error
+	redirects        map[string]string
+	patchedFields    *[]string
+	series           []Series
 }
 
-func (model MockArticleModel) All() ([]Article, error) { return model.articles, model.fetchError }
+func (model MockArticleModel) List(filter ArticleFilter, params webserverutils.PageParams) (ArticlePage, error) {
+	articles := []Article{}
+	for _, article := range model.articles {
+		if hasTags(article, filter.Tags) {
+			articles = append(articles, article)
+		}
+	}
+	page := ArticlePage{Total: len(articles)}
+	start := 0
+	if params.Cursor != nil {
+		for idx, article := range articles {
+			if article.ID == params.Cursor.ID {
+				start = idx + 1
+			}
+		}
+	}
+	page.Articles = articles[start:]
+	if len(page.Articles) > params.Limit {
+		page.Articles = page.Articles[:params.Limit]
+		page.NextCursor = webserverutils.EncodeCursor(webserverutils.Cursor{ID: page.Articles[params.Limit-1].ID})
+	}
+	return page, model.fetchError
+}
+func (model MockArticleModel) Tags() ([]TagCount, error) {
+	counts := map[string]int{}
+	tags := []TagCount{}
+	for _, article := range model.articles {
+		for _, tag := range article.Tags {
+			if counts[tag] == 0 {
+				tags = append(tags, TagCount{Tag: tag})
+			}
+			counts[tag]++
+		}
+	}
+	for idx := range tags {
+		tags[idx].Count = counts[tags[idx].Tag]
+	}
+	return tags, model.fetchError
+}
+func hasTags(article Article, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range article.Tags {
+			found = found || t == tag
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+func (model MockArticleModel) Search(query string, params webserverutils.PageParams) (SearchPage, error) {
+	page := SearchPage{}
+	for _, article := range model.articles {
+		if strings.Contains(article.Body, query) {
+			page.Results = append(page.Results, SearchResult{ID: article.ID, URI: article.URI, Snippet: article.Body})
+		}
+	}
+	page.Total = len(page.Results)
+	return page, model.fetchError
+}
 func (model MockArticleModel) Get(uri string) (Article, error) {
 	for _, article := range model.articles {
 		if article.URI == uri {
@@ -32,10 +95,13 @@ func (model MockArticleModel) Get(uri string) (Article, error) {
 	}
 	return Article{}, errors.New("no rows in result set")
 }
-func (model MockArticleModel) Update(uri string, a Article) (Article, error) {
+func (model MockArticleModel) Update(uri string, a Article, lastModified time.Time) (Article, error) {
 	for _, article := range model.articles {
 		fmt.Println(article.URI)
 		if article.URI == uri {
+			if !article.DateWritten().Equal(lastModified) {
+				return Article{}, ErrPreconditionFailed
+			}
 			article = a
 			ts := time.Now()
 			article.DateUpdated = &ts
@@ -44,6 +110,72 @@ func (model MockArticleModel) Update(uri string, a Article) (Article, error) {
 	}
 	return a, errors.New("no rows in result set")
 }
+func (model MockArticleModel) Patch(uri string, a Article, fields []string, lastModified time.Time) (Article, error) {
+	if model.patchedFields != nil {
+		*model.patchedFields = fields
+	}
+	return model.Update(uri, a, lastModified)
+}
+func (model MockArticleModel) Rename(uri string, newURI string) (Article, error) {
+	for _, article := range model.articles {
+		if article.URI == uri {
+			article.URI = newURI
+			return article, model.updateError
+		}
+	}
+	return Article{}, errors.New("no rows in result set")
+}
+func (model MockArticleModel) ResolveRedirect(oldURI string) (string, error) {
+	if uri, ok := model.redirects[oldURI]; ok {
+		return uri, nil
+	}
+	return "", errors.New("no rows in result set")
+}
+func (model MockArticleModel) GetSeries(slug string) (Series, error) {
+	for _, series := range model.series {
+		if series.Slug == slug {
+			return series, model.fetchError
+		}
+	}
+	return Series{}, errors.New("no rows in result set")
+}
+func (model MockArticleModel) SaveSeries(s Series) (Series, error) {
+	s.ID = 1
+	s.DateCreated = time.Now()
+	return s, model.saveError
+}
+func (model MockArticleModel) UpdateSeries(slug string, s Series) (Series, error) {
+	for _, series := range model.series {
+		if series.Slug == slug {
+			return s, model.updateError
+		}
+	}
+	return s, errors.New("no rows in result set")
+}
+func (model MockArticleModel) ValidateSeries(s Series) []error { return model.validationErrors }
+func (model MockArticleModel) Related(uri string, limit int) ([]RelatedArticle, error) {
+	related := []RelatedArticle{}
+	source, err := model.Get(uri)
+	if err != nil {
+		return related, err
+	}
+	for _, article := range model.articles {
+		shared := []string{}
+		for _, tag := range article.Tags {
+			if article.URI != uri && hasTags(source, []string{tag}) {
+				shared = append(shared, tag)
+			}
+		}
+		if len(shared) > 0 && len(related) < limit {
+			related = append(related, RelatedArticle{
+				ArticleRef: ArticleRef{URI: article.URI, Title: article.Title},
+				SharedTags: shared,
+				Score:      float64(len(shared)),
+			})
+		}
+	}
+	return related, model.fetchError
+}
 func (model MockArticleModel) Validate(a Article) []error { return model.validationErrors }
 func (model MockArticleModel) Save(a Article) (Article, error) {
 	a.ID = 1
@@ -51,6 +183,14 @@ func (model MockArticleModel) Save(a Article) (Article, error) {
 	return a, model.saveError
 }
 
+func articleETag(t *testing.T, a Article) string {
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return webserverutils.ETag(b)
+}
+
 func TestGetArticlesHandlerSuccess(t *testing.T) {
 	model := MockArticleModel{
 		articles: []Article{
@@ -96,6 +236,146 @@ func TestGetArticlesHandlerSuccess(t *testing.T) {
 	}
 }
 
+func TestGetArticlesHandlerPaginated(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:      1,
+				URI:     "some-article-1",
+				Title:   "Some Article: Part 1",
+				Summary: "A Short Summary",
+				Body:    "A Body",
+			},
+			{
+				ID:      2,
+				URI:     "some-article-2",
+				Title:   "Some Article: Part 2",
+				Summary: "A Short Summary",
+				Body:    "A Body",
+			},
+		},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles?limit=1&fields=uri,title", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	GetArticlesHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 200
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Result().StatusCode)
+	}
+
+	if total := rr.Header().Get("X-Total-Count"); total != "2" {
+		t.Errorf("expected total count '2' but received '%s'", total)
+	}
+	if link := rr.Header().Get("Link"); !strings.Contains(link, `rel="next"`) || !strings.Contains(link, "cursor=") {
+		t.Errorf("expected a next link but received '%s'", link)
+	}
+
+	var respBody []map[string]interface{}
+	err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(respBody) != 1 {
+		t.Fatalf("expected %d articles but received %d", 1, len(respBody))
+	}
+	if _, ok := respBody[0]["body"]; ok {
+		t.Errorf("expected body to be omitted but received %v", respBody[0])
+	}
+	if respBody[0]["uri"] != "some-article-1" {
+		t.Errorf("expected article '%s' but received '%v'", "some-article-1", respBody[0]["uri"])
+	}
+}
+
+func TestGetArticlesHandlerInvalidSort(t *testing.T) {
+	model := MockArticleModel{}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles?sort=body", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	GetArticlesHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 400
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Result().StatusCode)
+	}
+}
+
+func TestGetArticlesHandlerTagFilter(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:    1,
+				URI:   "some-article-1",
+				Title: "Some Article: Part 1",
+				Tags:  []string{"go", "testing"},
+			},
+			{
+				ID:    2,
+				URI:   "some-article-2",
+				Title: "Some Article: Part 2",
+				Tags:  []string{"go"},
+			},
+		},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles?tag=go&tag=testing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	GetArticlesHandler(model).ServeHTTP(rr, req)
+
+	var respBody []Article
+	err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(respBody) != 1 || respBody[0].URI != "some-article-1" {
+		t.Errorf("expected only '%s' but received %v", "some-article-1", respBody)
+	}
+}
+
+func TestGetTagsHandlerSuccess(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{ID: 1, URI: "some-article-1", Tags: []string{"go", "testing"}},
+			{ID: 2, URI: "some-article-2", Tags: []string{"go"}},
+		},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles/tags", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	GetTagsHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 200
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Result().StatusCode)
+	}
+
+	var respBody []TagCount
+	err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(respBody) != 2 || respBody[0] != (TagCount{Tag: "go", Count: 2}) {
+		t.Errorf("unexpected tag counts %v", respBody)
+	}
+}
+
 func TestGetArticlesHandlerFailure(t *testing.T) {
 	model := MockArticleModel{
 		articles: []Article{
@@ -132,6 +412,69 @@ func TestGetArticlesHandlerFailure(t *testing.T) {
 	}
 }
 
+func TestGetArticlesHandlerMalformedCursor(t *testing.T) {
+	model := MockArticleModel{fetchError: webserverutils.NewRequestError("malformed cursor")}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles?sort=title", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	GetArticlesHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 400
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Result().StatusCode)
+	}
+}
+
+func TestSearchArticlesHandlerSuccess(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{ID: 1, URI: "some-article-1", Body: "notes on postgres"},
+			{ID: 2, URI: "some-article-2", Body: "notes on go"},
+		},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles/search?q=postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	SearchArticlesHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 200
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Result().StatusCode)
+	}
+
+	var respBody []SearchResult
+	err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(respBody) != 1 || respBody[0].URI != "some-article-1" {
+		t.Errorf("expected only '%s' but received %v", "some-article-1", respBody)
+	}
+}
+
+func TestSearchArticlesHandlerMissingQuery(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/v1/articles/search?q=", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	SearchArticlesHandler(MockArticleModel{}).ServeHTTP(rr, req)
+
+	expectedCode := 400
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Result().StatusCode)
+	}
+}
+
 func TestGetArticleHandlerSuccess(t *testing.T) {
 	model := MockArticleModel{
 		articles: []Article{
@@ -437,6 +780,7 @@ func TestUpdateArticleHandlerSuccess(t *testing.T) {
 		t.Fatal(err)
 	}
 	req = mux.SetURLVars(req, map[string]string{"articleURI": targetURI})
+	req.Header.Set("If-Match", articleETag(t, model.articles[1]))
 
 	rr := httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
@@ -487,6 +831,7 @@ func TestUpdateArticleHandlerMismatchURI(t *testing.T) {
 		t.Fatal(err)
 	}
 	req = mux.SetURLVars(req, map[string]string{"articleURI": targetURI})
+	req.Header.Set("If-Match", articleETag(t, model.articles[1]))
 
 	rr := httptest.NewRecorder()
 	handler.ServeHTTP(rr, req)
@@ -585,3 +930,492 @@ func TestUpdateArticleHandlerNotFound(t *testing.T) {
 		t.Errorf("expected response body '%s' but received '%s'", expectedBody, respBody)
 	}
 }
+
+func TestUpdateArticleHandlerPreconditions(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:      1,
+				URI:     "some-article-1",
+				Title:   "Some Article: Part 1",
+				Summary: "A Short Summary",
+				Body:    "A Body",
+			},
+		},
+	}
+	handler := UpdateArticleHandler(model)
+
+	stale := model.articles[0]
+	stale.Summary = "An Older Summary"
+	cases := map[string]int{
+		"":                                       428,
+		articleETag(t, stale):                    412,
+		"W/" + articleETag(t, model.articles[0]): 412,
+		articleETag(t, model.articles[0]):        200,
+		"*":                                      200,
+	}
+	for ifMatch, expectedCode := range cases {
+		reqBody := `
+			{
+				"title": "Some article title",
+				"uri": "some-article-1",
+				"summary": "some summary",
+				"body": "some thoughts"
+			}
+		`
+		req, err := http.NewRequest("PUT", "/api/v1/article/some-article-1", bytes.NewBufferString(reqBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != expectedCode {
+			t.Errorf("expected status code %d for If-Match '%s' but received %d", expectedCode, ifMatch, rr.Code)
+		}
+		if expectedCode == 200 && rr.Header().Get("ETag") == "" {
+			t.Errorf("expected an ETag on the updated article")
+		}
+	}
+}
+
+func TestGetArticleHandlerNotModified(t *testing.T) {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:          1,
+				URI:         "some-article-1",
+				Title:       "Some Article: Part 1",
+				Summary:     "A Short Summary",
+				Body:        "A Body",
+				DateCreated: created,
+			},
+		},
+	}
+
+	headers := []map[string]string{
+		{"If-None-Match": articleETag(t, model.articles[0])},
+		{"If-None-Match": `"something-else", ` + articleETag(t, model.articles[0])},
+		{"If-Modified-Since": created.Format(http.TimeFormat)},
+	}
+	for _, header := range headers {
+		req, err := http.NewRequest("GET", "/api/v1/article/some-article-1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+
+		rr := httptest.NewRecorder()
+		GetArticleHandler(model).ServeHTTP(rr, req)
+
+		expectedCode := 304
+		if rr.Code != expectedCode {
+			t.Errorf("expected status code %d for %v but received %d", expectedCode, header, rr.Code)
+		}
+		if rr.Body.Len() != 0 {
+			t.Errorf("expected an empty body but received '%s'", rr.Body.String())
+		}
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/article/some-article-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+	req.Header.Set("If-None-Match", `"stale"`)
+	rr := httptest.NewRecorder()
+	GetArticleHandler(model).ServeHTTP(rr, req)
+
+	if rr.Code != 200 {
+		t.Errorf("expected status code %d but received %d", 200, rr.Code)
+	}
+	if rr.Header().Get("ETag") != articleETag(t, model.articles[0]) {
+		t.Errorf("expected ETag '%s' but received '%s'", articleETag(t, model.articles[0]), rr.Header().Get("ETag"))
+	}
+	if rr.Header().Get("Last-Modified") != created.Format(http.TimeFormat) {
+		t.Errorf("expected Last-Modified '%s' but received '%s'", created.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+	}
+}
+
+func TestGetArticleHandlerSeriesChanged(t *testing.T) {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	seriesChanged := created.Add(24 * time.Hour)
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:            1,
+				URI:           "some-article-1",
+				Title:         "Some Article: Part 1",
+				Body:          "A Body",
+				DateCreated:   created,
+				Series:        &SeriesRef{Slug: "some-series", Title: "Some Series", Part: 1, Parts: 2},
+				seriesChanged: seriesChanged,
+			},
+		},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/article/some-article-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+	req.Header.Set("If-Modified-Since", created.Format(http.TimeFormat))
+	rr := httptest.NewRecorder()
+	GetArticleHandler(model).ServeHTTP(rr, req)
+
+	if rr.Code != 200 {
+		t.Errorf("expected status code %d once the series changed but received %d", 200, rr.Code)
+	}
+	if rr.Header().Get("Last-Modified") != seriesChanged.Format(http.TimeFormat) {
+		t.Errorf("expected Last-Modified '%s' but received '%s'", seriesChanged.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+	}
+	if !model.articles[0].DateWritten().Equal(created) {
+		t.Errorf("expected edits to still be checked against '%s' but received '%s'", created, model.articles[0].DateWritten())
+	}
+}
+
+func TestGetArticleHandlerRedirect(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:      1,
+				URI:     "some-article-1",
+				Title:   "Some Article: Part 1",
+				Summary: "A Short Summary",
+				Body:    "A Body",
+			},
+		},
+		redirects: map[string]string{
+			"some-old-article": "some-article-1",
+			"some-loop":        "some-loop",
+		},
+	}
+
+	cases := map[string]int{
+		"some-old-article": 301,
+		"some-loop":        404,
+		"some-article-3":   404,
+	}
+	for targetArticle, expectedCode := range cases {
+		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/articles/%s", targetArticle), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": targetArticle})
+
+		rr := httptest.NewRecorder()
+		GetArticleHandler(model).ServeHTTP(rr, req)
+
+		if rr.Code != expectedCode {
+			t.Errorf("expected status code %d for '%s' but received %d", expectedCode, targetArticle, rr.Code)
+		}
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles/some-old-article?fields=uri", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-old-article"})
+
+	rr := httptest.NewRecorder()
+	GetArticleHandler(model).ServeHTTP(rr, req)
+
+	expectedLocation := "/api/v1/articles/some-article-1?fields=uri"
+	if location := rr.Header().Get("Location"); location != expectedLocation {
+		t.Errorf("expected location '%s' but received '%s'", expectedLocation, location)
+	}
+}
+
+func TestRenameArticleHandlerSuccess(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:      1,
+				URI:     "some-article-1",
+				Title:   "Some Article: Part 1",
+				Summary: "A Short Summary",
+				Body:    "A Body",
+			},
+		},
+	}
+
+	req, err := http.NewRequest("POST", "/api/v1/articles/some-article-1/rename", bytes.NewBufferString(`{"uri": "some-better-uri"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+
+	rr := httptest.NewRecorder()
+	RenameArticleHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 200
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Code)
+	}
+	expectedLocation := "/api/v1/articles/some-better-uri"
+	if location := rr.Header().Get("Location"); location != expectedLocation {
+		t.Errorf("expected location '%s' but received '%s'", expectedLocation, location)
+	}
+}
+
+func TestRenameArticleHandlerNotFound(t *testing.T) {
+	req, err := http.NewRequest("POST", "/api/v1/articles/some-article-3/rename", bytes.NewBufferString(`{"uri": "some-better-uri"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-3"})
+
+	rr := httptest.NewRecorder()
+	RenameArticleHandler(MockArticleModel{}).ServeHTTP(rr, req)
+
+	expectedCode := 404
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Code)
+	}
+}
+
+func TestPatchArticleHandlerSuccess(t *testing.T) {
+	patches := map[string]string{
+		"application/merge-patch+json": `{"summary": "A Better Summary", "tags": ["go"]}`,
+		"application/json-patch+json": `[
+			{"op": "replace", "path": "/summary", "value": "A Better Summary"},
+			{"op": "add", "path": "/tags", "value": ["go"]}
+		]`,
+	}
+	for contentType, patch := range patches {
+		patchedFields := []string{}
+		model := MockArticleModel{
+			articles: []Article{
+				{
+					ID:      1,
+					URI:     "some-article-1",
+					Title:   "Some Article: Part 1",
+					Summary: "A Short Summary",
+					Body:    "A Body",
+				},
+			},
+			patchedFields: &patchedFields,
+		}
+
+		req, err := http.NewRequest("PATCH", "/api/v1/articles/some-article-1", bytes.NewBufferString(patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("If-Match", articleETag(t, model.articles[0]))
+
+		rr := httptest.NewRecorder()
+		PatchArticleHandler(model).ServeHTTP(rr, req)
+
+		expectedCode := 200
+		if rr.Code != expectedCode {
+			t.Errorf("expected status code %d for %s but received %d: %s", expectedCode, contentType, rr.Code, rr.Body.String())
+		}
+
+		var respBody Article
+		err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+		if respBody.Summary != "A Better Summary" || respBody.Body != "A Body" {
+			t.Errorf("expected only the summary to change but received %+v", respBody)
+		}
+		if strings.Join(patchedFields, ",") != "summary,tags" {
+			t.Errorf("expected only summary and tags to be written but received %v", patchedFields)
+		}
+	}
+}
+
+func TestPatchArticleHandlerErrors(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{
+				ID:      1,
+				URI:     "some-article-1",
+				Title:   "Some Article: Part 1",
+				Summary: "A Short Summary",
+				Body:    "A Body",
+			},
+		},
+		validationErrors: []error{},
+	}
+
+	cases := []struct {
+		contentType  string
+		patch        string
+		expectedCode int
+	}{
+		{"application/json", `{"summary": "A Better Summary"}`, 415},
+		{"application/merge-patch+json", `{"uri": "some-other-uri"}`, 422},
+		{"application/merge-patch+json", `{"id": 5}`, 422},
+		{"application/merge-patch+json", `{"author": "someone"}`, 422},
+		{"application/merge-patch+json", `not json`, 422},
+		{"application/json-patch+json", `[{"op": "test", "path": "/title", "value": "Another Title"}]`, 422},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("PATCH", "/api/v1/articles/some-article-1", bytes.NewBufferString(c.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+		req.Header.Set("Content-Type", c.contentType)
+		req.Header.Set("If-Match", articleETag(t, model.articles[0]))
+
+		rr := httptest.NewRecorder()
+		PatchArticleHandler(model).ServeHTTP(rr, req)
+
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s %s but received %d", c.expectedCode, c.contentType, c.patch, rr.Code)
+		}
+	}
+}
+
+func TestGetSeriesHandlerSuccess(t *testing.T) {
+	model := MockArticleModel{
+		series: []Series{
+			{
+				ID:    1,
+				Slug:  "some-article",
+				Title: "Some Article",
+				Articles: []ArticleRef{
+					{URI: "some-article-1", Title: "Some Article: Part 1"},
+					{URI: "some-article-2", Title: "Some Article: Part 2"},
+				},
+			},
+		},
+	}
+
+	for slug, expectedCode := range map[string]int{"some-article": 200, "some-other-article": 404} {
+		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/articles/series/%s", slug), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"seriesSlug": slug})
+
+		rr := httptest.NewRecorder()
+		GetSeriesHandler(model).ServeHTTP(rr, req)
+
+		if rr.Code != expectedCode {
+			t.Errorf("expected status code %d for '%s' but received %d", expectedCode, slug, rr.Code)
+		}
+		if expectedCode != 200 {
+			continue
+		}
+
+		var respBody Series
+		err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+		if len(respBody.Articles) != 2 || respBody.Articles[1].URI != "some-article-2" {
+			t.Errorf("expected both parts in order but received %v", respBody.Articles)
+		}
+	}
+}
+
+func TestCreateSeriesHandlerSuccess(t *testing.T) {
+	reqBody := `
+		{
+			"slug": "some-article",
+			"title": "Some Article",
+			"description": "a two parter",
+			"articles": [{"uri": "some-article-1"}, {"uri": "some-article-2"}]
+		}
+	`
+	req, err := http.NewRequest("POST", "/api/v1/articles/series", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	CreateSeriesHandler(MockArticleModel{}).ServeHTTP(rr, req)
+
+	expectedCode := 201
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Code)
+	}
+}
+
+func TestUpdateSeriesHandlerInvalidPayload(t *testing.T) {
+	model := MockArticleModel{
+		validationErrors: []error{
+			errors.New("test error 1"),
+		},
+	}
+	req, err := http.NewRequest("PUT", "/api/v1/articles/series/some-article", bytes.NewBufferString(`{"slug": "some-article"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"seriesSlug": "some-article"})
+
+	rr := httptest.NewRecorder()
+	UpdateSeriesHandler(model).ServeHTTP(rr, req)
+
+	expectedCode := 422
+	expectedBody := "Invalid Request Body: test error 1"
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Code)
+	}
+	if respBody := strings.TrimSpace(rr.Body.String()); respBody != expectedBody {
+		t.Errorf("expected response body '%s' but received '%s'", expectedBody, respBody)
+	}
+}
+
+func TestGetRelatedArticlesHandler(t *testing.T) {
+	model := MockArticleModel{
+		articles: []Article{
+			{ID: 1, URI: "some-article-1", Tags: []string{"go", "testing"}},
+			{ID: 2, URI: "some-article-2", Tags: []string{"go"}},
+			{ID: 3, URI: "some-article-3", Tags: []string{"testing"}},
+			{ID: 4, URI: "some-article-4", Tags: []string{"postgres"}},
+		},
+	}
+
+	cases := []struct {
+		uri           string
+		query         string
+		expectedCode  int
+		expectedCount int
+	}{
+		{"some-article-1", "", 200, 2},
+		{"some-article-1", "?limit=1", 200, 1},
+		{"some-article-1", "?limit=many", 400, 0},
+		{"some-article-4", "", 200, 0},
+		{"some-article-5", "", 404, 0},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/articles/%s/related%s", c.uri, c.query), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": c.uri})
+
+		rr := httptest.NewRecorder()
+		GetRelatedArticlesHandler(model).ServeHTTP(rr, req)
+
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for '%s%s' but received %d", c.expectedCode, c.uri, c.query, rr.Code)
+		}
+		if c.expectedCode != 200 {
+			continue
+		}
+		var respBody []RelatedArticle
+		err = json.Unmarshal(rr.Body.Bytes(), &respBody)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+		if len(respBody) != c.expectedCount {
+			t.Errorf("expected %d related articles for '%s%s' but received %d", c.expectedCount, c.uri, c.query, len(respBody))
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package articles
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Average adult silent reading speed
+const wordsPerMinute = 200
+
+// Details derived from an article's markdown body whenever it is saved
+type ArticleMetadata struct {
+	WordCount      int              `json:"wordCount"`
+	ReadingMinutes int              `json:"readingMinutes"`
+	Outline        []OutlineHeading `json:"outline"`
+	FirstImage     *string          `json:"firstImage"`
+}
+
+// A heading in the body, with the anchor a renderer would give it
+type OutlineHeading struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+var (
+	fencePattern        = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	atxHeadingPattern   = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	setextPattern       = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	blockMarkerPattern  = regexp.MustCompile(`^\s*([-*+>]|\d+[.)])(\s|$)`)
+	imagePattern        = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+	htmlImagePattern    = regexp.MustCompile(`(?i)<img\b[^>]*\bsrc\s*=\s*["']([^"']+)["']`)
+	linkPattern         = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	htmlTagPattern      = regexp.MustCompile(`<[^>]+>`)
+	anchorStripPattern  = regexp.MustCompile(`[^\p{L}\p{N}\- ]`)
+	inlineMarkupPattern = regexp.MustCompile("[*_`~]")
+)
+
+// Walks the markdown once, skipping fenced code for the outline and word count
+func computeMetadata(body string) ArticleMetadata {
+	metadata := ArticleMetadata{Outline: []OutlineHeading{}}
+	anchors := map[string]int{}
+	addHeading := func(level int, text string) {
+		text = strings.TrimSpace(inlineMarkupPattern.ReplaceAllString(linkPattern.ReplaceAllString(text, "$1"), ""))
+		if text == "" {
+			return
+		}
+		metadata.Outline = append(metadata.Outline, OutlineHeading{Level: level, Text: text, Anchor: headingAnchor(text, anchors)})
+	}
+
+	inFence := ""
+	previous := ""
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			if inFence == "" {
+				inFence = match[1]
+			} else if match[1] == inFence {
+				inFence = ""
+			}
+			previous = ""
+			continue
+		}
+		if inFence != "" {
+			continue
+		}
+
+		if metadata.FirstImage == nil {
+			if match := imagePattern.FindStringSubmatch(line); match != nil {
+				metadata.FirstImage = &match[1]
+			} else if match := htmlImagePattern.FindStringSubmatch(line); match != nil {
+				metadata.FirstImage = &match[1]
+			}
+		}
+
+		metadata.WordCount += countWords(line)
+		if match := atxHeadingPattern.FindStringSubmatch(line); match != nil {
+			addHeading(len(match[1]), match[2])
+			previous = ""
+			continue
+		}
+		// a line of = or - underlines the paragraph line above it, otherwise it's a rule
+		if match := setextPattern.FindStringSubmatch(line); match != nil {
+			if strings.TrimSpace(previous) != "" && !blockMarkerPattern.MatchString(previous) {
+				level := 1
+				if strings.HasPrefix(match[1], "-") {
+					level = 2
+				}
+				addHeading(level, previous)
+			}
+			previous = ""
+			continue
+		}
+		previous = line
+	}
+
+	metadata.ReadingMinutes = int(math.Ceil(float64(metadata.WordCount) / wordsPerMinute))
+	return metadata
+}
+
+// Counts words in a line of prose, ignoring link targets, tags and markup
+func countWords(line string) int {
+	line = linkPattern.ReplaceAllString(line, "$1")
+	line = htmlTagPattern.ReplaceAllString(line, " ")
+	count := 0
+	for _, field := range strings.Fields(line) {
+		for _, r := range field {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// A GitHub style anchor: lowercase, punctuation dropped, spaces as hyphens and
+// a numeric suffix when the same heading appears more than once
+func headingAnchor(text string, seen map[string]int) string {
+	anchor := strings.ReplaceAll(anchorStripPattern.ReplaceAllString(strings.ToLower(text), ""), " ", "-")
+	count := seen[anchor]
+	seen[anchor] = count + 1
+	if count > 0 {
+		return fmt.Sprintf("%s-%d", anchor, count)
+	}
+	return anchor
+}
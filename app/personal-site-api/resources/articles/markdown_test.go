@@ -0,0 +1,75 @@
+package articles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeMetadata(t *testing.T) {
+	body := strings.Join([]string{
+		"# Table Driven Tests",
+		"",
+		"Some notes on [testing in Go](https://go.dev/doc/tutorial/add-a-test).",
+		"",
+		"![a diagram](/assets/diagram.png \"diagram\")",
+		"",
+		"Setup",
+		"-----",
+		"",
+		"```go",
+		"# not a heading",
+		"func TestSomething(t *testing.T) {}",
+		"```",
+		"",
+		"## Setup ##",
+		"",
+		"- a list item",
+		"---",
+		"<img src=\"/assets/later.png\">",
+	}, "\n")
+
+	metadata := computeMetadata(body)
+
+	expectedOutline := []OutlineHeading{
+		{Level: 1, Text: "Table Driven Tests", Anchor: "table-driven-tests"},
+		{Level: 2, Text: "Setup", Anchor: "setup"},
+		{Level: 2, Text: "Setup", Anchor: "setup-1"},
+	}
+	if len(metadata.Outline) != len(expectedOutline) {
+		t.Fatalf("expected outline %v but received %v", expectedOutline, metadata.Outline)
+	}
+	for idx, heading := range expectedOutline {
+		if metadata.Outline[idx] != heading {
+			t.Errorf("expected heading %v but received %v", heading, metadata.Outline[idx])
+		}
+	}
+
+	// title (3) + notes (6) + image alt (2) + setup (1) + setup (1) + list item (3)
+	expectedWordCount := 16
+	if metadata.WordCount != expectedWordCount {
+		t.Errorf("expected %d words but received %d", expectedWordCount, metadata.WordCount)
+	}
+	if metadata.ReadingMinutes != 1 {
+		t.Errorf("expected %d reading minutes but received %d", 1, metadata.ReadingMinutes)
+	}
+	if metadata.FirstImage == nil || *metadata.FirstImage != "/assets/diagram.png" {
+		t.Errorf("expected first image '%s' but received %v", "/assets/diagram.png", metadata.FirstImage)
+	}
+}
+
+func TestComputeMetadataEmpty(t *testing.T) {
+	metadata := computeMetadata("")
+	if metadata.WordCount != 0 || metadata.ReadingMinutes != 0 || metadata.FirstImage != nil {
+		t.Errorf("expected empty metadata but received %+v", metadata)
+	}
+	if metadata.Outline == nil {
+		t.Errorf("expected an empty outline rather than nil")
+	}
+}
+
+func TestComputeMetadataReadingMinutes(t *testing.T) {
+	metadata := computeMetadata(strings.Repeat("word ", 401))
+	if metadata.ReadingMinutes != 3 {
+		t.Errorf("expected %d reading minutes but received %d", 3, metadata.ReadingMinutes)
+	}
+}
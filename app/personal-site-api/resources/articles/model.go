@@ -3,89 +3,883 @@ package articles
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
 	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
 )
 
 // A struct to model the object
 type Article struct {
+	ID      int      `json:"id"`
+	URI     string   `json:"uri"`
+	Title   string   `json:"title"`
+	Summary string   `json:"summary"`
+	Body    string   `json:"body"`
+	Tags    []string `json:"tags"`
+	ArticleMetadata
+	DateCreated time.Time   `json:"dateCreated"`
+	DateUpdated *time.Time  `json:"dateUpdated"`
+	Series      *SeriesRef  `json:"series,omitempty"`
+	Previous    *ArticleRef `json:"previous,omitempty"`
+	Next        *ArticleRef `json:"next,omitempty"`
+	Social      *SocialMeta `json:"social,omitempty"`
+
+	// when the series block last changed, which moves LastModified too
+	seriesChanged time.Time
+}
+
+// Enough of an article to link to it
+type ArticleRef struct {
+	URI     string `json:"uri"`
+	Title   string `json:"title"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Another article worth reading next and why it was picked
+type RelatedArticle struct {
+	ArticleRef
+	SharedTags []string `json:"sharedTags"`
+	Score      float64  `json:"score"`
+}
+
+const (
+	DefaultRelatedLimit = 5
+	MaxRelatedLimit     = 20
+)
+
+// Where an article sits within its series
+type SeriesRef struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	Part  int    `json:"part"`
+	Parts int    `json:"parts"`
+}
+
+// An ordered run of articles meant to be read together
+type Series struct {
+	ID          int          `json:"id"`
+	Slug        string       `json:"slug"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Articles    []ArticleRef `json:"articles"`
+	DateCreated time.Time    `json:"dateCreated"`
+	DateUpdated *time.Time   `json:"dateUpdated"`
+}
+
+// When the article was last written, which edits are checked against
+func (a Article) DateWritten() time.Time {
+	if a.DateUpdated != nil {
+		return *a.DateUpdated
+	}
+	return a.DateCreated
+}
+
+// When anything served with the article last changed, including its series
+func (a Article) LastModified() time.Time {
+	if a.seriesChanged.After(a.DateWritten()) {
+		return a.seriesChanged
+	}
+	return a.DateWritten()
+}
+
+// Returned by Update when the article changed since the caller last read it
+var ErrPreconditionFailed = errors.New("article has been modified since it was last fetched")
+
+// Where an article lives and when it last changed, for the sitemap
+type SitemapEntry struct {
+	URI          string
+	LastModified time.Time
+}
+
+// A tag and the number of articles carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// An article matching a search, with the matching passage of the body highlighted
+type SearchResult struct {
 	ID          int        `json:"id"`
 	URI         string     `json:"uri"`
 	Title       string     `json:"title"`
 	Summary     string     `json:"summary"`
-	Body        string     `json:"body"`
+	Tags        []string   `json:"tags"`
 	DateCreated time.Time  `json:"dateCreated"`
 	DateUpdated *time.Time `json:"dateUpdated"`
+	Rank        float32    `json:"rank"`
+	Snippet     string     `json:"snippet"`
+}
+
+// A single page of search results
+type SearchPage struct {
+	Results    []SearchResult
+	Total      int
+	NextCursor string
+}
+
+// Search results are always ranked, best match first
+var SearchPageOptions = webserverutils.PageOptions{
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	DefaultSort:  "-rank",
+	SortFields:   []string{"rank"},
+}
+
+// Narrows a listing to articles carrying every one of the tags
+type ArticleFilter struct {
+	Tags []string
+}
+
+// A single page of a listing
+type ArticlePage struct {
+	Articles   []Article
+	Total      int
+	NextCursor string
+}
+
+// Paging and sorting accepted by the article listing
+var ArticlePageOptions = webserverutils.PageOptions{
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	DefaultSort:  "-created",
+	SortFields:   []string{"created", "updated", "title"},
+	Fields: []string{"id", "uri", "title", "summary", "body", "tags", "wordCount", "readingMinutes",
+		"outline", "firstImage", "dateCreated", "dateUpdated"},
+}
+
+// Fields an editor may change through Update or Patch
+var EditableArticleFields = []string{"title", "summary", "body", "tags"}
+
+// Columns backing the editable fields stored on the articles table
+var articleColumns = map[string]string{
+	"title":   "title",
+	"summary": "summary",
+	"body":    "body_md",
+}
+
+// Column expressions backing each sort option
+var articleSortColumns = map[string]string{
+	"created": "dt_created",
+	"updated": "COALESCE(dt_updated, dt_created)",
+	"title":   "title",
 }
 
+// lowercase words separated by single hyphens, e.g. "go" or "unit-testing", also used for series slugs
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const maxTagLength = 32
+
+// Tags of each article as an ordered array
+const articleTagsColumn = `ARRAY(SELECT tag FROM article_tags WHERE article_id = articles.id ORDER BY tag)`
+
+// Stored metadata derived from the body, in ArticleMetadata field order
+const articleMetadataColumns = `word_count, reading_minutes, outline, first_image`
+
 // An interface to refresent the Model (for mocking in test)
 type ArticleDataAccessLayer interface {
-	All() ([]Article, error)
+	List(filter ArticleFilter, params webserverutils.PageParams) (page ArticlePage, err error)
 	Get(uri string) (result Article, err error)
+	Tags() (tags []TagCount, err error)
+	Search(query string, params webserverutils.PageParams) (page SearchPage, err error)
 	Save(a Article) (result Article, err error)
-	Update(uri string, a Article) (result Article, err error)
+	Update(uri string, a Article, lastModified time.Time) (result Article, err error)
+	Patch(uri string, a Article, fields []string, lastModified time.Time) (result Article, err error)
+	Rename(uri string, newURI string) (result Article, err error)
+	ResolveRedirect(oldURI string) (uri string, err error)
+	Related(uri string, limit int) (related []RelatedArticle, err error)
 	Validate(a Article) (errs []error)
+	GetSeries(slug string) (series Series, err error)
+	SaveSeries(s Series) (result Series, err error)
+	UpdateSeries(slug string, s Series) (result Series, err error)
+	ValidateSeries(s Series) (errs []error)
 }
 
 // The Model with Database Implementation
 type ArticleModel struct {
-	DB *pgx.Conn
+	DB   *pgx.Conn
+	Site cfg.SiteConfig
+
+	// called with an article's uri after its body is saved, e.g. to send webmentions
+	Published func(uri string)
+
+	// related articles by uri, cleared whenever any article is written
+	relatedMu         sync.Mutex
+	relatedCache      map[string][]RelatedArticle
+	relatedGeneration int
 }
 
-func (model *ArticleModel) All() (articles []Article, err error) {
+func (model *ArticleModel) List(filter ArticleFilter, params webserverutils.PageParams) (page ArticlePage, err error) {
+	sortColumn, ok := articleSortColumns[params.Sort]
+	if !ok {
+		return page, webserverutils.NewRequestError(fmt.Sprintf("cannot sort by '%s'", params.Sort))
+	}
 
-	stmt := `
-		SELECT id, uri, title, summary, body_md, dt_created, dt_updated
+	conditions := []string{}
+	args := []interface{}{}
+	if len(filter.Tags) > 0 {
+		// each tag only matches one row per article, so repeats would never match
+		tags := uniqueTags(filter.Tags)
+		args = append(args, tags, len(tags))
+		conditions = append(conditions, `id IN (
+			SELECT article_id FROM article_tags
+			WHERE tag = ANY($1)
+			GROUP BY article_id
+			HAVING count(*) = $2
+		)`)
+	}
+
+	countStmt := fmt.Sprintf("SELECT count(*) FROM articles %s;", whereClause(conditions))
+	err = model.DB.QueryRow(context.Background(), countStmt, args...).Scan(&page.Total)
+	if err != nil {
+		return page, err
+	}
+
+	// skip reading bodies when the caller hasn't asked for them
+	bodyColumn := "''"
+	if params.HasField("body") {
+		bodyColumn = "body_md"
+	}
+
+	direction, comparison := "ASC", ">"
+	if params.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if params.Cursor != nil {
+		value, err := cursorValue(params.Sort, params.Cursor.Value)
+		if err != nil {
+			return page, err
+		}
+		args = append(args, value, params.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+	args = append(args, params.Limit+1)
+
+	stmt := fmt.Sprintf(`
+		SELECT id, uri, title, summary, %s, %s, %s, dt_created, dt_updated
 		FROM articles
-		ORDER BY dt_created DESC;
-	`
-	rows, err := model.DB.Query(context.Background(), stmt)
+		%s
+		ORDER BY %s %s, id %s
+		LIMIT $%d;
+	`, bodyColumn, articleTagsColumn, articleMetadataColumns, whereClause(conditions), sortColumn, direction, direction, len(args))
+	rows, err := model.DB.Query(context.Background(), stmt, args...)
 	if err != nil {
-		return articles, err
+		return page, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var article Article
 		err = rows.Scan(&article.ID, &article.URI, &article.Title, &article.Summary,
-			&article.Body, &article.DateCreated, &article.DateUpdated)
-		articles = append(articles, article)
+			&article.Body, &article.Tags, &article.WordCount, &article.ReadingMinutes, &article.Outline,
+			&article.FirstImage, &article.DateCreated, &article.DateUpdated)
+		if err != nil {
+			return page, err
+		}
+		page.Articles = append(page.Articles, article)
+	}
+	if err = rows.Err(); err != nil {
+		return page, err
+	}
+
+	// the extra row only tells us another page exists
+	if len(page.Articles) > params.Limit {
+		page.Articles = page.Articles[:params.Limit]
+		page.NextCursor = webserverutils.EncodeCursor(articleCursor(page.Articles[params.Limit-1], params.Sort))
+	}
+	return page, nil
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// Builds the cursor pointing just past the given article
+func articleCursor(a Article, sort string) webserverutils.Cursor {
+	switch sort {
+	case "title":
+		return webserverutils.Cursor{Value: a.Title, ID: a.ID}
+	case "updated":
+		if a.DateUpdated != nil {
+			return webserverutils.Cursor{Value: a.DateUpdated.Format(time.RFC3339Nano), ID: a.ID}
+		}
 	}
-	return articles, err
+	return webserverutils.Cursor{Value: a.DateCreated.Format(time.RFC3339Nano), ID: a.ID}
+}
+
+// Converts a cursor value back into the type of the sort column
+func cursorValue(sort string, value string) (interface{}, error) {
+	if sort == "title" {
+		return value, nil
+	}
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, webserverutils.NewRequestError("malformed cursor")
+	}
+	return ts, nil
 }
 
 func (model *ArticleModel) Get(uri string) (article Article, err error) {
-	stmt := `
-		SELECT id, uri, title, summary, body_md, dt_created, dt_updated
+	stmt := fmt.Sprintf(`
+		SELECT id, uri, title, summary, body_md, %s, %s, dt_created, dt_updated, dt_series_changed
 		FROM articles
 		WHERE uri = $1;
-	`
+	`, articleTagsColumn, articleMetadataColumns)
+	var seriesChanged *time.Time
 	err = model.DB.QueryRow(context.Background(), stmt, uri).
 		Scan(&article.ID, &article.URI, &article.Title, &article.Summary,
-			&article.Body, &article.DateCreated, &article.DateUpdated)
+			&article.Body, &article.Tags, &article.WordCount, &article.ReadingMinutes, &article.Outline,
+			&article.FirstImage, &article.DateCreated, &article.DateUpdated, &seriesChanged)
+	if err != nil {
+		return article, err
+	}
+	if seriesChanged != nil {
+		article.seriesChanged = *seriesChanged
+	}
+	err = model.loadSeriesNavigation(&article)
+	article.Social = socialMeta(article, model.Site)
 	return article, err
 }
 
-func (model *ArticleModel) Update(uri string, a Article) (result Article, err error) {
+// Fills in the series an article belongs to and its neighbours within it
+func (model *ArticleModel) loadSeriesNavigation(article *Article) (err error) {
+	stmt := `
+		SELECT series.slug, series.title, member.position,
+			(SELECT count(*) FROM article_series_members WHERE series_id = series.id),
+			previous.uri, previous.title, next.uri, next.title,
+			GREATEST(COALESCE(previous.dt_updated, previous.dt_created), COALESCE(next.dt_updated, next.dt_created))
+		FROM article_series_members AS member
+		JOIN article_series AS series ON series.id = member.series_id
+		LEFT JOIN article_series_members AS previous_member
+			ON previous_member.series_id = member.series_id AND previous_member.position = member.position - 1
+		LEFT JOIN articles AS previous ON previous.id = previous_member.article_id
+		LEFT JOIN article_series_members AS next_member
+			ON next_member.series_id = member.series_id AND next_member.position = member.position + 1
+		LEFT JOIN articles AS next ON next.id = next_member.article_id
+		WHERE member.article_id = $1;
+	`
+	var series SeriesRef
+	var previousURI, previousTitle, nextURI, nextTitle *string
+	var neighbourWritten *time.Time
+	err = model.DB.QueryRow(context.Background(), stmt, article.ID).Scan(
+		&series.Slug, &series.Title, &series.Part, &series.Parts,
+		&previousURI, &previousTitle, &nextURI, &nextTitle, &neighbourWritten,
+	)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	article.Series = &series
+	if neighbourWritten != nil && neighbourWritten.After(article.seriesChanged) {
+		article.seriesChanged = *neighbourWritten
+	}
+	if previousURI != nil {
+		article.Previous = &ArticleRef{URI: *previousURI, Title: *previousTitle}
+	}
+	if nextURI != nil {
+		article.Next = &ArticleRef{URI: *nextURI, Title: *nextTitle}
+	}
+	return nil
+}
+
+// Every tag in use with the number of articles carrying it, most used first
+func (model *ArticleModel) Tags() (tags []TagCount, err error) {
+	stmt := `
+		SELECT tag, count(*)
+		FROM article_tags
+		GROUP BY tag
+		ORDER BY count(*) DESC, tag;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt)
+	if err != nil {
+		return tags, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag TagCount
+		err = rows.Scan(&tag.Tag, &tag.Count)
+		if err != nil {
+			return tags, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// A page of articles for the sitemap, in the order they were created
+func (model *ArticleModel) SitemapEntries(offset int, limit int) (entries []SitemapEntry, err error) {
+	stmt := `
+		SELECT uri, COALESCE(dt_updated, dt_created)
+		FROM articles
+		ORDER BY id
+		OFFSET $1 LIMIT $2;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, offset, limit)
+	if err != nil {
+		return entries, err
+	}
+	defer rows.Close()
+
+	entries = []SitemapEntry{}
+	for rows.Next() {
+		var entry SitemapEntry
+		err = rows.Scan(&entry.URI, &entry.LastModified)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// When each page of the sitemap last changed, splitting articles into pages
+// of the given size in the order they were created. There's one time per page.
+func (model *ArticleModel) SitemapPages(size int) (pages []time.Time, err error) {
+	stmt := `
+		SELECT max(last_modified)
+		FROM (
+			SELECT COALESCE(dt_updated, dt_created) AS last_modified, (row_number() OVER (ORDER BY id) - 1) / $1 AS page
+			FROM articles
+		) paged
+		GROUP BY page
+		ORDER BY page;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, size)
+	if err != nil {
+		return pages, err
+	}
+	defer rows.Close()
+
+	pages = []time.Time{}
+	for rows.Next() {
+		var lastModified time.Time
+		err = rows.Scan(&lastModified)
+		if err != nil {
+			return pages, err
+		}
+		pages = append(pages, lastModified)
+	}
+	return pages, rows.Err()
+}
+
+// Ranks articles against the query, weighting title over summary over body
+func (model *ArticleModel) Search(query string, params webserverutils.PageParams) (page SearchPage, err error) {
+	tsquery, err := buildSearchQuery(query)
+	if err != nil {
+		return page, err
+	}
+
+	countStmt := `
+		SELECT count(*)
+		FROM articles
+		WHERE search_vector @@ to_tsquery('english', $1);
+	`
+	err = model.DB.QueryRow(context.Background(), countStmt, tsquery).Scan(&page.Total)
+	if err != nil {
+		return page, err
+	}
+
+	args := []interface{}{tsquery}
+	where := ""
+	if params.Cursor != nil {
+		rank, err := strconv.ParseFloat(params.Cursor.Value, 32)
+		if err != nil {
+			return page, webserverutils.NewRequestError("malformed cursor")
+		}
+		args = append(args, float32(rank), params.Cursor.ID)
+		where = "WHERE (rank, id) < ($2, $3)"
+	}
+	args = append(args, params.Limit+1)
+
+	// headlines are only built for the rows on the page
+	stmt := fmt.Sprintf(`
+		SELECT id, uri, title, summary, tags, dt_created, dt_updated, rank,
+			ts_headline('english', body_md, query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=10, MaxWords=30')
+		FROM (
+			SELECT id, uri, title, summary, body_md, %s AS tags, dt_created, dt_updated,
+				query, ts_rank_cd(search_vector, query) AS rank
+			FROM articles, to_tsquery('english', $1) AS query
+			WHERE search_vector @@ query
+		) AS matches
+		%s
+		ORDER BY rank DESC, id DESC
+		LIMIT $%d;
+	`, articleTagsColumn, where, len(args))
+	rows, err := model.DB.Query(context.Background(), stmt, args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result SearchResult
+		err = rows.Scan(&result.ID, &result.URI, &result.Title, &result.Summary, &result.Tags,
+			&result.DateCreated, &result.DateUpdated, &result.Rank, &result.Snippet)
+		if err != nil {
+			return page, err
+		}
+		page.Results = append(page.Results, result)
+	}
+	if err = rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Results) > params.Limit {
+		page.Results = page.Results[:params.Limit]
+		last := page.Results[params.Limit-1]
+		page.NextCursor = webserverutils.EncodeCursor(webserverutils.Cursor{
+			Value: strconv.FormatFloat(float64(last.Rank), 'g', -1, 32),
+			ID:    last.ID,
+		})
+	}
+	return page, nil
+}
+
+// Translates a search box query into tsquery syntax. Words are ANDed together,
+// "quoted words" must appear as a phrase, a trailing * matches any word with
+// that prefix and a leading - excludes articles containing the word.
+func buildSearchQuery(query string) (string, error) {
+	terms := []string{}
+	for idx, chunk := range strings.Split(query, `"`) {
+		// every other chunk sits between quotes
+		if idx%2 == 1 {
+			if phrase := searchWords(chunk); len(phrase) > 0 {
+				terms = append(terms, "("+strings.Join(phrase, " <-> ")+")")
+			}
+			continue
+		}
+		for _, field := range strings.Fields(chunk) {
+			negate := strings.HasPrefix(field, "-")
+			prefix := strings.HasSuffix(field, "*")
+			words := searchWords(field)
+			if len(words) == 0 {
+				continue
+			}
+			term := strings.Join(words, " <-> ")
+			if prefix {
+				term += ":*"
+			}
+			if len(words) > 1 {
+				term = "(" + term + ")"
+			}
+			if negate {
+				term = "!" + term
+			}
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return "", webserverutils.NewRequestError("search query must contain at least one word")
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+// Splits text into words, dropping anything that would be tsquery syntax
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// The tags in the order given, without repeats
+func uniqueTags(tags []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+// Replaces the tags of an article
+func saveTags(tx pgx.Tx, articleID int, tags []string) (err error) {
+	_, err = tx.Exec(context.Background(), "DELETE FROM article_tags WHERE article_id = $1;", articleID)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	stmt := `
+		INSERT INTO article_tags (article_id, tag)
+		SELECT DISTINCT $1::integer, unnest($2::text[]);
+	`
+	_, err = tx.Exec(context.Background(), stmt, articleID, tags)
+	return err
+}
+
+// Writes the article only if it is unchanged since lastModified, so concurrent
+// editors can't overwrite each other
+func (model *ArticleModel) Update(uri string, a Article, lastModified time.Time) (result Article, err error) {
 	if uri != a.URI {
-		return Article{}, webserverutils.NewRequestError("URI in path does not match URI in body.")
+		return Article{}, webserverutils.NewRequestError("URI in path does not match URI in body. Use rename to change it.")
 	}
+	return model.Patch(uri, a, EditableArticleFields, lastModified)
+}
 
+// Writes only the given fields of the article, under the same precondition as Update
+func (model *ArticleModel) Patch(uri string, a Article, fields []string, lastModified time.Time) (result Article, err error) {
 	todayDate := time.Now()
-	stmt := `
+	args := []interface{}{todayDate, uri, lastModified}
+	assignments := []string{"dt_updated=$1"}
+	tagsChanged := false
+	bodyChanged := false
+	for _, field := range fields {
+		switch field {
+		case "title":
+			args = append(args, a.Title)
+		case "summary":
+			args = append(args, a.Summary)
+		case "body":
+			// derived metadata is rewritten along with the body it describes
+			metadata := computeMetadata(a.Body)
+			args = append(args, metadata.WordCount, metadata.ReadingMinutes, metadata.Outline, metadata.FirstImage)
+			assignments = append(assignments, fmt.Sprintf("word_count=$%d, reading_minutes=$%d, outline=$%d, first_image=$%d",
+				len(args)-3, len(args)-2, len(args)-1, len(args)))
+			args = append(args, a.Body)
+			bodyChanged = true
+		case "tags":
+			tagsChanged = true
+			continue
+		default:
+			return result, webserverutils.NewRequestError(fmt.Sprintf("field '%s' cannot be updated", field))
+		}
+		assignments = append(assignments, fmt.Sprintf("%s=$%d", articleColumns[field], len(args)))
+	}
+
+	stmt := fmt.Sprintf(`
 		UPDATE articles
-		SET title=$1, summary=$2, body_md=$3, dt_updated=$4
-		WHERE uri=$5
-		RETURNING id, title, summary, body_md, dt_created, dt_updated
+		SET %s
+		WHERE uri=$2 AND COALESCE(dt_updated, dt_created)=$3
+		RETURNING id
+	`, strings.Join(assignments, ", "))
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, stmt, args...).Scan(&result.ID)
+	if err == pgx.ErrNoRows {
+		var exists bool
+		existsErr := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM articles WHERE uri=$1);", uri).Scan(&exists)
+		if existsErr != nil {
+			return result, existsErr
+		}
+		if exists {
+			return result, ErrPreconditionFailed
+		}
+	}
+	if err != nil {
+		return result, database.TranslateError(err)
+	}
+
+	if tagsChanged {
+		err = saveTags(tx, result.ID, a.Tags)
+		if err != nil {
+			return result, err
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	model.clearRelated()
+	if bodyChanged {
+		model.notifyPublished(uri)
+	}
+	// read back so the response matches what a later GET returns
+	return model.Get(uri)
+}
+
+// Other articles ranked by the tags they share with this one, then by how much of
+// their vocabulary overlaps (Jaccard similarity of the search lexemes, which cover
+// title, summary and body). Results are cached in memory until an article is written.
+func (model *ArticleModel) Related(uri string, limit int) (related []RelatedArticle, err error) {
+	if limit < 1 || limit > MaxRelatedLimit {
+		return related, webserverutils.NewRequestError(fmt.Sprintf("limit must be between 1 and %d", MaxRelatedLimit))
+	}
+
+	model.relatedMu.Lock()
+	cached, ok := model.relatedCache[uri]
+	generation := model.relatedGeneration
+	model.relatedMu.Unlock()
+	if !ok {
+		cached, err = model.rankRelated(uri)
+		if err != nil {
+			return related, err
+		}
+		model.relatedMu.Lock()
+		// don't cache a ranking an article write has already made stale
+		if generation == model.relatedGeneration {
+			if model.relatedCache == nil {
+				model.relatedCache = map[string][]RelatedArticle{}
+			}
+			model.relatedCache[uri] = cached
+		}
+		model.relatedMu.Unlock()
+	}
+
+	if len(cached) > limit {
+		cached = cached[:limit]
+	}
+	return cached, nil
+}
+
+func (model *ArticleModel) rankRelated(uri string) (related []RelatedArticle, err error) {
+	var id int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", uri).Scan(&id)
+	if err != nil {
+		return related, err
+	}
+
+	stmt := `
+		WITH source AS (
+			SELECT id, tsvector_to_array(search_vector) AS lexemes
+			FROM articles
+			WHERE id = $1
+		),
+		candidates AS (
+			SELECT articles.uri, articles.title, articles.summary, articles.dt_created, shared.tags,
+				overlap.lexemes::float8 / GREATEST(cardinality(source.lexemes) + length(articles.search_vector) - overlap.lexemes, 1) AS similarity
+			FROM source, articles,
+			LATERAL (
+				SELECT ARRAY(
+					SELECT tag FROM article_tags
+					WHERE article_id = articles.id
+						AND tag IN (SELECT tag FROM article_tags WHERE article_id = source.id)
+					ORDER BY tag
+				) AS tags
+			) AS shared,
+			LATERAL (
+				SELECT count(*) AS lexemes
+				FROM unnest(tsvector_to_array(articles.search_vector)) AS lexeme
+				WHERE lexeme = ANY(source.lexemes)
+			) AS overlap
+			WHERE articles.id <> source.id
+		)
+		SELECT uri, title, summary, tags, cardinality(tags) + similarity AS score
+		FROM candidates
+		WHERE cardinality(tags) + similarity > 0
+		ORDER BY score DESC, dt_created DESC
+		LIMIT $2;
 	`
-	err = model.DB.QueryRow(
-		context.Background(),
-		stmt,
-		a.Title, a.Summary, a.Body, todayDate, uri,
-	).Scan(&result.ID, &result.Title, &result.Summary, &result.Body, &result.DateCreated, &result.DateUpdated)
-	return result, err
+	rows, err := model.DB.Query(context.Background(), stmt, id, MaxRelatedLimit)
+	if err != nil {
+		return related, err
+	}
+	defer rows.Close()
+
+	related = []RelatedArticle{}
+	for rows.Next() {
+		var r RelatedArticle
+		err = rows.Scan(&r.URI, &r.Title, &r.Summary, &r.SharedTags, &r.Score)
+		if err != nil {
+			return related, err
+		}
+		related = append(related, r)
+	}
+	return related, rows.Err()
+}
+
+func (model *ArticleModel) clearRelated() {
+	model.relatedMu.Lock()
+	model.relatedCache = nil
+	model.relatedGeneration++
+	model.relatedMu.Unlock()
+}
+
+// Moves the article to a new URI, keeping a redirect from the old one. Redirects
+// point at the article rather than a URI so renaming twice never builds a chain.
+func (model *ArticleModel) Rename(uri string, newURI string) (result Article, err error) {
+	if newURI == "" {
+		return result, webserverutils.NewRequestError("missing new article uri")
+	}
+	if strings.Contains(newURI, "/") {
+		return result, webserverutils.NewRequestError("article uri cannot contain '/'")
+	}
+	if newURI == uri {
+		return result, webserverutils.NewRequestError("new uri matches the current uri")
+	}
+
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	var articleID int
+	err = tx.QueryRow(ctx, "SELECT id FROM articles WHERE uri = $1 FOR UPDATE;", uri).Scan(&articleID)
+	if err != nil {
+		return result, err
+	}
+
+	// a redirect from the new uri to this article would loop once it goes live,
+	// and one to another article would be hijacked
+	var redirectTarget int
+	err = tx.QueryRow(ctx, "SELECT article_id FROM article_redirects WHERE old_uri = $1;", newURI).Scan(&redirectTarget)
+	if err == nil && redirectTarget != articleID {
+		return result, webserverutils.NewRequestError("new uri already redirects to another article")
+	}
+	if err != nil && err != pgx.ErrNoRows {
+		return result, err
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM article_redirects WHERE old_uri = $1;", newURI)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE articles SET uri = $1, dt_updated = $2 WHERE id = $3;", newURI, time.Now(), articleID)
+	if err != nil {
+		return result, database.TranslateError(err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO article_redirects (old_uri, article_id, dt_created)
+		VALUES ($1, $2, $3);
+	`, uri, articleID, time.Now())
+	if err != nil {
+		return result, database.TranslateError(err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	model.clearRelated()
+	return model.Get(newURI)
+}
+
+// The current URI of an article that used to live at oldURI
+func (model *ArticleModel) ResolveRedirect(oldURI string) (uri string, err error) {
+	stmt := `
+		SELECT articles.uri
+		FROM article_redirects
+		JOIN articles ON articles.id = article_redirects.article_id
+		WHERE article_redirects.old_uri = $1;
+	`
+	err = model.DB.QueryRow(context.Background(), stmt, oldURI).Scan(&uri)
+	return uri, err
 }
 
 func (model *ArticleModel) Validate(a Article) (errs []error) {
@@ -102,28 +896,277 @@ func (model *ArticleModel) Validate(a Article) (errs []error) {
 	if a.URI == "" {
 		errs = append(errs, errors.New("missing article uri"))
 	}
+	for _, tag := range a.Tags {
+		if len(tag) > maxTagLength || !tagPattern.MatchString(tag) {
+			errs = append(errs, fmt.Errorf("invalid tag '%s': tags are lowercase words separated by hyphens, at most %d characters", tag, maxTagLength))
+		}
+	}
 	return errs
 }
 
+// Fills in the stored metadata of articles written before it was computed.
+// Their dates are left alone since the articles themselves haven't changed.
+// Bodies that really have no words are looked at again each time, which is cheap.
+func (model *ArticleModel) BackfillMetadata() (count int, err error) {
+	ctx := context.Background()
+	rows, err := model.DB.Query(ctx, `SELECT id, body_md FROM articles WHERE word_count = 0 AND body_md <> ''`)
+	if err != nil {
+		return 0, err
+	}
+	bodies := map[int]string{}
+	for rows.Next() {
+		var id int
+		var body string
+		err = rows.Scan(&id, &body)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		bodies[id] = body
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, body := range bodies {
+		metadata := computeMetadata(body)
+		_, err = model.DB.Exec(
+			ctx,
+			`UPDATE articles SET word_count=$2, reading_minutes=$3, outline=$4, first_image=$5 WHERE id=$1`,
+			id, metadata.WordCount, metadata.ReadingMinutes, metadata.Outline, metadata.FirstImage,
+		)
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
 func (model *ArticleModel) Save(a Article) (newArticle Article, err error) {
 	newArticle = a
+	newArticle.ArticleMetadata = computeMetadata(a.Body)
 
 	stmt := `
-		INSERT INTO articles (title, uri, summary, body_md, dt_created) 
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO articles (title, uri, summary, body_md, word_count, reading_minutes, outline, first_image, dt_created) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, dt_created
 	`
 	todayDate := time.Now()
 
-	err = model.DB.QueryRow(
-		context.Background(),
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return newArticle, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
+		ctx,
 		stmt,
-		a.Title, a.URI, a.Summary, a.Body, todayDate,
+		a.Title, a.URI, a.Summary, a.Body, newArticle.WordCount, newArticle.ReadingMinutes,
+		newArticle.Outline, newArticle.FirstImage, todayDate,
 	).Scan(&newArticle.ID, &newArticle.DateCreated)
 
 	if err != nil {
-		err = database.TranslateError(err)
+		return newArticle, database.TranslateError(err)
 	}
 
-	return newArticle, err
+	err = saveTags(tx, newArticle.ID, a.Tags)
+	if err != nil {
+		return newArticle, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return newArticle, err
+	}
+	model.clearRelated()
+	model.notifyPublished(newArticle.URI)
+	return newArticle, nil
+}
+
+func (model *ArticleModel) notifyPublished(uri string) {
+	if model.Published != nil {
+		model.Published(uri)
+	}
+}
+
+// A series with its articles in reading order
+func (model *ArticleModel) GetSeries(slug string) (series Series, err error) {
+	stmt := `
+		SELECT id, slug, title, description, dt_created, dt_updated
+		FROM article_series
+		WHERE slug = $1;
+	`
+	err = model.DB.QueryRow(context.Background(), stmt, slug).Scan(
+		&series.ID, &series.Slug, &series.Title, &series.Description, &series.DateCreated, &series.DateUpdated,
+	)
+	if err != nil {
+		return series, err
+	}
+
+	stmt = `
+		SELECT articles.uri, articles.title, articles.summary
+		FROM article_series_members AS member
+		JOIN articles ON articles.id = member.article_id
+		WHERE member.series_id = $1
+		ORDER BY member.position;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, series.ID)
+	if err != nil {
+		return series, err
+	}
+	defer rows.Close()
+
+	series.Articles = []ArticleRef{}
+	for rows.Next() {
+		var ref ArticleRef
+		err = rows.Scan(&ref.URI, &ref.Title, &ref.Summary)
+		if err != nil {
+			return series, err
+		}
+		series.Articles = append(series.Articles, ref)
+	}
+	return series, rows.Err()
+}
+
+func (model *ArticleModel) SaveSeries(s Series) (result Series, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	stmt := `
+		INSERT INTO article_series (slug, title, description, dt_created)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int
+	now := time.Now()
+	err = tx.QueryRow(ctx, stmt, s.Slug, s.Title, s.Description, now).Scan(&id)
+	if err != nil {
+		return result, database.TranslateError(err)
+	}
+	err = saveSeriesMembers(tx, id, s.Articles)
+	if err != nil {
+		return result, err
+	}
+	err = markSeriesChanged(tx, id, now)
+	if err != nil {
+		return result, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	return model.GetSeries(s.Slug)
+}
+
+// Replaces the details and reading order of a series
+func (model *ArticleModel) UpdateSeries(slug string, s Series) (result Series, err error) {
+	if slug != s.Slug {
+		return result, webserverutils.NewRequestError("slug in path does not match slug in body.")
+	}
+
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	stmt := `
+		UPDATE article_series
+		SET title=$1, description=$2, dt_updated=$3
+		WHERE slug=$4
+		RETURNING id
+	`
+	var id int
+	now := time.Now()
+	err = tx.QueryRow(ctx, stmt, s.Title, s.Description, now, slug).Scan(&id)
+	if err != nil {
+		return result, err
+	}
+	// articles leaving the series change as much as those joining it
+	err = markSeriesChanged(tx, id, now)
+	if err != nil {
+		return result, err
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM article_series_members WHERE series_id = $1;", id)
+	if err != nil {
+		return result, err
+	}
+	err = saveSeriesMembers(tx, id, s.Articles)
+	if err != nil {
+		return result, err
+	}
+	err = markSeriesChanged(tx, id, now)
+	if err != nil {
+		return result, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	return model.GetSeries(slug)
+}
+
+// Moves the Last-Modified of every article in the series
+func markSeriesChanged(tx pgx.Tx, seriesID int, at time.Time) (err error) {
+	stmt := `
+		UPDATE articles SET dt_series_changed = $2
+		WHERE id IN (SELECT article_id FROM article_series_members WHERE series_id = $1);
+	`
+	_, err = tx.Exec(context.Background(), stmt, seriesID, at)
+	return err
+}
+
+// Numbers the articles from 1 in the order given
+func saveSeriesMembers(tx pgx.Tx, seriesID int, articles []ArticleRef) (err error) {
+	uris := []string{}
+	for _, ref := range articles {
+		uris = append(uris, ref.URI)
+	}
+	stmt := `
+		INSERT INTO article_series_members (series_id, article_id, position)
+		SELECT $1, articles.id, members.position
+		FROM unnest($2::text[]) WITH ORDINALITY AS members(uri, position)
+		JOIN articles ON articles.uri = members.uri
+	`
+	tag, err := tx.Exec(context.Background(), stmt, seriesID, uris)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates unique constraint") {
+			return webserverutils.NewRequestError("an article can only belong to one series")
+		}
+		return err
+	}
+	if int(tag.RowsAffected()) != len(uris) {
+		return webserverutils.NewRequestError("series includes an article that does not exist")
+	}
+	return nil
+}
+
+func (model *ArticleModel) ValidateSeries(s Series) (errs []error) {
+	errs = []error{}
+	if !tagPattern.MatchString(s.Slug) {
+		errs = append(errs, errors.New("series slug must be lowercase words separated by hyphens"))
+	}
+	if s.Title == "" {
+		errs = append(errs, errors.New("missing series title"))
+	}
+	seen := map[string]bool{}
+	for _, ref := range s.Articles {
+		if ref.URI == "" {
+			errs = append(errs, errors.New("missing series article uri"))
+		} else if seen[ref.URI] {
+			errs = append(errs, fmt.Errorf("article '%s' appears in the series more than once", ref.URI))
+		}
+		seen[ref.URI] = true
+	}
+	return errs
 }
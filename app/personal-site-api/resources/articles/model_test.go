@@ -30,3 +30,80 @@ func TestValidateWithErrors(t *testing.T) {
 		t.Errorf("Expected %d errors during validation but received %d", expectedErrCount, len(errs))
 	}
 }
+
+func TestValidateTags(t *testing.T) {
+	model := ArticleModel{}
+	a := Article{
+		URI:     "some-uri",
+		Title:   "Some title",
+		Summary: "some-ary",
+		Body:    "some body",
+		Tags:    []string{"go", "unit-testing", "Go", "two words", "trailing-"},
+	}
+	expectedErrCount := 3
+	errs := model.Validate(a)
+	if len(errs) != expectedErrCount {
+		t.Errorf("Expected %d errors during validation but received %d", expectedErrCount, len(errs))
+	}
+}
+
+func TestUniqueTags(t *testing.T) {
+	tags := uniqueTags([]string{"go", "testing", "go"})
+	if len(tags) != 2 || tags[0] != "go" || tags[1] != "testing" {
+		t.Errorf("expected [go testing] but received %v", tags)
+	}
+}
+
+func TestBuildSearchQuery(t *testing.T) {
+	cases := map[string]string{
+		"postgres":                    "postgres",
+		"Go testing":                  "go & testing",
+		`"table driven" tests`:        "(table <-> driven) & tests",
+		"bench* -fuzz":                "bench:* & !fuzz",
+		"full-text search":            "(full <-> text) & search",
+		"'drop' & | ! <-> :* (table)": "drop & table",
+	}
+	for query, expected := range cases {
+		tsquery, err := buildSearchQuery(query)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %s", query, err)
+		}
+		if tsquery != expected {
+			t.Errorf("expected '%s' to become '%s' but received '%s'", query, expected, tsquery)
+		}
+	}
+
+	_, err := buildSearchQuery(`"" * -`)
+	if err == nil {
+		t.Errorf("expected an error for a query without words")
+	}
+}
+
+func TestValidateSeries(t *testing.T) {
+	model := ArticleModel{}
+	series := Series{
+		Slug:  "Some Series",
+		Title: "",
+		Articles: []ArticleRef{
+			{URI: "some-article-1"},
+			{URI: "some-article-2"},
+			{URI: "some-article-1"},
+			{URI: ""},
+		},
+	}
+	expectedErrCount := 4
+	errs := model.ValidateSeries(series)
+	if len(errs) != expectedErrCount {
+		t.Errorf("Expected %d errors during validation but received %d", expectedErrCount, len(errs))
+	}
+}
+
+func TestRelatedLimitBounds(t *testing.T) {
+	model := ArticleModel{}
+	for _, limit := range []int{0, MaxRelatedLimit + 1} {
+		_, err := model.Related("some-uri", limit)
+		if err == nil {
+			t.Errorf("expected an error for limit %d", limit)
+		}
+	}
+}
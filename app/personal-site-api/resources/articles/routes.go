@@ -2,6 +2,7 @@ package articles
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
 )
 
@@ -11,11 +12,20 @@ import (
 // 	}
 // }
 
-func InitializeRoutes(router *mux.Router, model ArticleDataAccessLayer) {
+func InitializeRoutes(router *mux.Router, model ArticleDataAccessLayer, site cfg.SiteConfig) {
 	// router.HandleFunc("", NoContentHandler()).Methods("OPTIONS")
 	router.HandleFunc("", GetArticlesHandler(model)).Methods("GET")
 	router.HandleFunc("", middleware.AuthMiddleware(CreateArticleHandler(model))).Methods("POST")
+	router.HandleFunc("/tags", GetTagsHandler(model)).Methods("GET")
+	router.HandleFunc("/search", SearchArticlesHandler(model)).Methods("GET")
+	router.HandleFunc("/series", middleware.AuthMiddleware(CreateSeriesHandler(model))).Methods("POST")
+	router.HandleFunc("/series/{seriesSlug}", GetSeriesHandler(model)).Methods("GET")
+	router.HandleFunc("/series/{seriesSlug}", middleware.AuthMiddleware(UpdateSeriesHandler(model))).Methods("PUT")
 	// router.HandleFunc("/{articleURI}", NoContentHandler()).Methods("OPTIONS")
 	router.HandleFunc("/{articleURI}", GetArticleHandler(model)).Methods("GET")
 	router.HandleFunc("/{articleURI}", middleware.AuthMiddleware(UpdateArticleHandler(model))).Methods("PUT")
+	router.HandleFunc("/{articleURI}", middleware.AuthMiddleware(PatchArticleHandler(model))).Methods("PATCH")
+	router.HandleFunc("/{articleURI}/related", GetRelatedArticlesHandler(model)).Methods("GET")
+	router.HandleFunc("/{articleURI}/og.png", GetArticleCardHandler(model, site)).Methods("GET")
+	router.HandleFunc("/{articleURI}/rename", middleware.AuthMiddleware(RenameArticleHandler(model))).Methods("POST")
 }
@@ -0,0 +1,66 @@
+package articles
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
+)
+
+// Card images use the size Open Graph and Twitter both crop to
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+)
+
+// Meta tags for link previews, keyed by the property (Open Graph) or name
+// (Twitter) they go in, ready for the frontend to write into the page head
+type SocialMeta struct {
+	OpenGraph map[string]string `json:"openGraph"`
+	Twitter   map[string]string `json:"twitter"`
+}
+
+// Where the article's card image is served, versioned by when the article
+// last changed so previews are refetched after an edit
+func cardURL(a Article, site cfg.SiteConfig) string {
+	return fmt.Sprintf("%s/api/v1/articles/%s/og.png?v=%d", site.APIBaseURL, url.PathEscape(a.URI), a.LastModified().Unix())
+}
+
+// Nothing is returned until the site's addresses are configured, as the tags
+// need absolute URLs
+func socialMeta(a Article, site cfg.SiteConfig) *SocialMeta {
+	if site.ArticleBaseURL == "" {
+		return nil
+	}
+	meta := &SocialMeta{
+		OpenGraph: map[string]string{
+			"og:type":                "article",
+			"og:title":               a.Title,
+			"og:description":         a.Summary,
+			"og:url":                 site.ArticleURL(a.URI),
+			"article:published_time": a.DateCreated.UTC().Format(time.RFC3339),
+			"article:modified_time":  a.LastModified().UTC().Format(time.RFC3339),
+		},
+		Twitter: map[string]string{
+			"twitter:card":        "summary",
+			"twitter:title":       a.Title,
+			"twitter:description": a.Summary,
+		},
+	}
+	if site.Name != "" {
+		meta.OpenGraph["og:site_name"] = site.Name
+	}
+	if site.APIBaseURL != "" {
+		image := cardURL(a, site)
+		meta.OpenGraph["og:image"] = image
+		meta.OpenGraph["og:image:type"] = "image/png"
+		meta.OpenGraph["og:image:width"] = fmt.Sprint(CardWidth)
+		meta.OpenGraph["og:image:height"] = fmt.Sprint(CardHeight)
+		meta.OpenGraph["og:image:alt"] = a.Title
+		meta.Twitter["twitter:card"] = "summary_large_image"
+		meta.Twitter["twitter:image"] = image
+		meta.Twitter["twitter:image:alt"] = a.Title
+	}
+	return meta
+}
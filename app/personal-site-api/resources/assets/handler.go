@@ -0,0 +1,149 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/storage"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+// Assets can be replaced under the same name, so browsers revalidate daily
+const assetCacheControl = "public, max-age=86400"
+
+func ListAssetsHandler(model AssetDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		assets, err := model.List(vars["articleURI"])
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem fetching assets", http.StatusInternalServerError)
+			}
+			return
+		}
+		jbytes, err := json.Marshal(assets)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+func GetAssetHandler(model AssetDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		asset, blob, err := model.Get(vars["articleURI"], vars["assetName"])
+		if err != nil {
+			if err.Error() == "no rows in result set" || err == storage.ErrBlobNotFound {
+				http.Error(w, "asset not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem fetching asset", http.StatusInternalServerError)
+			}
+			return
+		}
+		defer blob.Close()
+
+		w.Header().Set("Cache-Control", assetCacheControl)
+		if webserverutils.CheckNotModified(w, r, `"`+asset.SHA256+`"`, asset.LastModified()) {
+			return
+		}
+		w.Header().Set("Content-Type", asset.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(asset.Size, 10))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		io.Copy(w, blob)
+	}
+}
+
+// Accepts a multipart form with the upload in a "file" field. The stored
+// content type comes from sniffing the bytes, not from what the client claims.
+func UploadAssetHandler(model AssetDataAccessLayer, maxBytes int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleURI := vars["articleURI"]
+
+		// leave room for the multipart framing around the file
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20)
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, "request must be multipart/form-data", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var name string
+		var content []byte
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, "problem reading upload", http.StatusBadRequest)
+				return
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+			name = part.FileName()
+			content, err = ioutil.ReadAll(io.LimitReader(part, maxBytes+1))
+			if err != nil {
+				http.Error(w, "problem reading upload", http.StatusBadRequest)
+				return
+			}
+			break
+		}
+		if content == nil {
+			http.Error(w, webserverutils.NewRequestError("missing file field").Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if int64(len(content)) > maxBytes {
+			http.Error(w, fmt.Sprintf("asset exceeds the %d byte limit", maxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		errs := model.Validate(name)
+		if len(errs) > 0 {
+			errMsgs := []string{}
+			for _, err := range errs {
+				errMsgs = append(errMsgs, err.Error())
+			}
+			msg := webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		contentType := http.DetectContentType(content)
+		if !IsAllowedContentType(contentType) {
+			http.Error(w, fmt.Sprintf("content type '%s' is not allowed", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		asset, err := model.Save(articleURI, name, contentType, content)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem saving asset", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		bytes, _ := json.Marshal(asset)
+		w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+asset.Name)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(bytes)
+	}
+}
@@ -0,0 +1,186 @@
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// the first bytes of a PNG, enough for content sniffing
+var pngContent = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+type MockAssetModel struct {
+	articles []string
+	assets   map[string][]byte
+	saveErr  error
+}
+
+func (model MockAssetModel) hasArticle(uri string) bool {
+	for _, article := range model.articles {
+		if article == uri {
+			return true
+		}
+	}
+	return false
+}
+func (model MockAssetModel) List(articleURI string) ([]Asset, error) {
+	if !model.hasArticle(articleURI) {
+		return nil, errors.New("no rows in result set")
+	}
+	assets := []Asset{}
+	for name, content := range model.assets {
+		assets = append(assets, Asset{Name: name, Size: int64(len(content))})
+	}
+	return assets, nil
+}
+func (model MockAssetModel) Get(articleURI string, name string) (Asset, io.ReadCloser, error) {
+	content, ok := model.assets[name]
+	if !model.hasArticle(articleURI) || !ok {
+		return Asset{}, nil, errors.New("no rows in result set")
+	}
+	asset := Asset{
+		Name:        name,
+		ContentType: http.DetectContentType(content),
+		Size:        int64(len(content)),
+		SHA256:      "abc123",
+		DateCreated: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	return asset, ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+func (model MockAssetModel) Save(articleURI string, name string, contentType string, content []byte) (Asset, error) {
+	if !model.hasArticle(articleURI) {
+		return Asset{}, errors.New("no rows in result set")
+	}
+	model.assets[name] = content
+	return Asset{Name: name, ContentType: contentType, Size: int64(len(content))}, model.saveErr
+}
+func (model MockAssetModel) Validate(name string) []error {
+	return (&AssetModel{}).Validate(name)
+}
+
+func uploadRequest(t *testing.T, articleURI string, fileName string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req, err := http.NewRequest("POST", "/api/v1/articles/"+articleURI+"/assets", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return mux.SetURLVars(req, map[string]string{"articleURI": articleURI})
+}
+
+func TestUploadAssetHandler(t *testing.T) {
+	model := MockAssetModel{
+		articles: []string{"some-article-1"},
+		assets:   map[string][]byte{},
+	}
+	handler := UploadAssetHandler(model, 64)
+
+	cases := []struct {
+		articleURI   string
+		fileName     string
+		content      []byte
+		expectedCode int
+	}{
+		{"some-article-1", "diagram.png", pngContent, 201},
+		{"some-article-1", "notes.png", []byte("just some text"), 415},
+		{"some-article-1", "huge.png", append(pngContent, make([]byte, 64)...), 413},
+		{"some-article-1", "my diagram!.png", pngContent, 422},
+		{"some-article-2", "diagram.png", pngContent, 404},
+	}
+	for _, c := range cases {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, uploadRequest(t, c.articleURI, c.fileName, c.content))
+
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for '%s' but received %d: %s", c.expectedCode, c.fileName, rr.Code, rr.Body.String())
+		}
+	}
+
+	if _, ok := model.assets["diagram.png"]; !ok {
+		t.Errorf("expected diagram.png to be stored")
+	}
+	if _, ok := model.assets["notes.png"]; ok {
+		t.Errorf("expected notes.png to be rejected")
+	}
+}
+
+func TestUploadAssetHandlerNotMultipart(t *testing.T) {
+	req, err := http.NewRequest("POST", "/api/v1/articles/some-article-1/assets", bytes.NewReader(pngContent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "image/png")
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+
+	rr := httptest.NewRecorder()
+	UploadAssetHandler(MockAssetModel{articles: []string{"some-article-1"}}, 64).ServeHTTP(rr, req)
+
+	expectedCode := 415
+	if rr.Code != expectedCode {
+		t.Errorf("expected status code %d but received %d", expectedCode, rr.Code)
+	}
+}
+
+func TestGetAssetHandler(t *testing.T) {
+	model := MockAssetModel{
+		articles: []string{"some-article-1"},
+		assets:   map[string][]byte{"diagram.png": pngContent},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/articles/some-article-1/assets/diagram.png", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1", "assetName": "diagram.png"})
+
+	rr := httptest.NewRecorder()
+	GetAssetHandler(model).ServeHTTP(rr, req)
+
+	if rr.Code != 200 {
+		t.Errorf("expected status code %d but received %d", 200, rr.Code)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), pngContent) {
+		t.Errorf("expected the stored content to be served")
+	}
+	expectedHeaders := map[string]string{
+		"Content-Type":  "image/png",
+		"Cache-Control": assetCacheControl,
+		"ETag":          `"abc123"`,
+		"Last-Modified": "Sun, 02 Jan 2022 03:04:05 GMT",
+	}
+	for header, expected := range expectedHeaders {
+		if value := rr.Header().Get(header); value != expected {
+			t.Errorf("expected %s '%s' but received '%s'", header, expected, value)
+		}
+	}
+
+	req.Header.Set("If-None-Match", `"abc123"`)
+	rr = httptest.NewRecorder()
+	GetAssetHandler(model).ServeHTTP(rr, req)
+	if rr.Code != 304 {
+		t.Errorf("expected status code %d but received %d", 304, rr.Code)
+	}
+
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1", "assetName": "missing.png"})
+	rr = httptest.NewRecorder()
+	GetAssetHandler(model).ServeHTTP(rr, req)
+	if rr.Code != 404 {
+		t.Errorf("expected status code %d but received %d", 404, rr.Code)
+	}
+}
@@ -0,0 +1,175 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/storage"
+)
+
+// A file uploaded alongside an article, such as an image it embeds
+type Asset struct {
+	Name        string     `json:"name"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	SHA256      string     `json:"sha256"`
+	DateCreated time.Time  `json:"dateCreated"`
+	DateUpdated *time.Time `json:"dateUpdated"`
+}
+
+// When the asset content was last written
+func (a Asset) LastModified() time.Time {
+	if a.DateUpdated != nil {
+		return *a.DateUpdated
+	}
+	return a.DateCreated
+}
+
+// Sniffed content types we're willing to store and serve back
+var AllowedContentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+}
+
+// plain file names only, so they're safe in both URLs and blob keys
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// An interface to refresent the Model (for mocking in test)
+type AssetDataAccessLayer interface {
+	List(articleURI string) (assets []Asset, err error)
+	Get(articleURI string, name string) (asset Asset, blob io.ReadCloser, err error)
+	Save(articleURI string, name string, contentType string, content []byte) (asset Asset, err error)
+	Validate(name string) (errs []error)
+}
+
+// The Model with Database Implementation, keeping file content in a blob store
+type AssetModel struct {
+	DB    *pgx.Conn
+	Store storage.BlobStore
+}
+
+// Assets are keyed by article id so they survive article renames
+func blobKey(articleID int, name string) string {
+	return fmt.Sprintf("articles/%d/%s", articleID, name)
+}
+
+func (model *AssetModel) List(articleURI string) (assets []Asset, err error) {
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return assets, err
+	}
+
+	stmt := `
+		SELECT name, content_type, size_bytes, sha256, dt_created, dt_updated
+		FROM article_assets
+		WHERE article_id = $1
+		ORDER BY name;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, articleID)
+	if err != nil {
+		return assets, err
+	}
+	defer rows.Close()
+
+	assets = []Asset{}
+	for rows.Next() {
+		var asset Asset
+		err = rows.Scan(&asset.Name, &asset.ContentType, &asset.Size, &asset.SHA256, &asset.DateCreated, &asset.DateUpdated)
+		if err != nil {
+			return assets, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, rows.Err()
+}
+
+// The asset's details and its content, which the caller must close
+func (model *AssetModel) Get(articleURI string, name string) (asset Asset, blob io.ReadCloser, err error) {
+	stmt := `
+		SELECT article_assets.article_id, name, content_type, size_bytes, sha256,
+			article_assets.dt_created, article_assets.dt_updated
+		FROM article_assets
+		JOIN articles ON articles.id = article_assets.article_id
+		WHERE articles.uri = $1 AND article_assets.name = $2;
+	`
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), stmt, articleURI, name).Scan(
+		&articleID, &asset.Name, &asset.ContentType, &asset.Size, &asset.SHA256, &asset.DateCreated, &asset.DateUpdated,
+	)
+	if err != nil {
+		return asset, nil, err
+	}
+	blob, err = model.Store.Get(blobKey(articleID, name))
+	return asset, blob, err
+}
+
+// Stores the content, replacing any asset of the same name on the article
+func (model *AssetModel) Save(articleURI string, name string, contentType string, content []byte) (asset Asset, err error) {
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return asset, err
+	}
+
+	err = model.Store.Put(blobKey(articleID, name), bytes.NewReader(content))
+	if err != nil {
+		return asset, err
+	}
+
+	sum := sha256.Sum256(content)
+	asset = Asset{
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	stmt := `
+		INSERT INTO article_assets (article_id, name, content_type, size_bytes, sha256, dt_created)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (article_id, name)
+		DO
+			UPDATE SET content_type = $3, size_bytes = $4, sha256 = $5, dt_updated = $6
+		RETURNING dt_created, dt_updated
+	`
+	err = model.DB.QueryRow(
+		context.Background(),
+		stmt,
+		articleID, asset.Name, asset.ContentType, asset.Size, asset.SHA256, time.Now(),
+	).Scan(&asset.DateCreated, &asset.DateUpdated)
+	if err != nil {
+		return asset, database.TranslateError(err)
+	}
+	return asset, nil
+}
+
+func (model *AssetModel) Validate(name string) (errs []error) {
+	errs = []error{}
+	if name == "" {
+		errs = append(errs, errors.New("missing asset file name"))
+	} else if !namePattern.MatchString(name) {
+		errs = append(errs, errors.New("asset names are up to 100 letters, digits, '.', '_' or '-' and can't start with punctuation"))
+	}
+	return errs
+}
+
+func IsAllowedContentType(contentType string) bool {
+	for _, t := range AllowedContentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
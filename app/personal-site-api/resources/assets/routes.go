@@ -0,0 +1,12 @@
+package assets
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+)
+
+func InitializeRoutes(router *mux.Router, model AssetDataAccessLayer, maxUploadBytes int64) {
+	router.HandleFunc("", ListAssetsHandler(model)).Methods("GET")
+	router.HandleFunc("", middleware.AuthMiddleware(UploadAssetHandler(model, maxUploadBytes))).Methods("POST")
+	router.HandleFunc("/{assetName}", GetAssetHandler(model)).Methods("GET")
+}
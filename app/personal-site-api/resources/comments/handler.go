@@ -0,0 +1,150 @@
+package comments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+// Without a status readers get the approved comments as threads. Moderators
+// pass ?status=pending, rejected, approved or all for a flat list.
+func ListCommentsHandler(model CommentDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		status := r.URL.Query().Get("status")
+		threaded := status == ""
+		if threaded {
+			status = StatusApproved
+		} else if status == "all" {
+			status = ""
+		} else if !IsStatus(status) {
+			http.Error(w, fmt.Sprintf("status must be one of %s or all", strings.Join(Statuses, ", ")), http.StatusBadRequest)
+			return
+		}
+
+		comments, err := model.List(vars["articleURI"], status)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem fetching comments", http.StatusInternalServerError)
+			}
+			return
+		}
+		if threaded {
+			comments = BuildThreads(comments)
+		}
+
+		jbytes, err := json.Marshal(comments)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+// New comments wait in the moderation queue, so this answers 202 rather than
+// 201. Honeypot submissions get the same answer but are never saved.
+func SubmitCommentHandler(model CommentDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var submission CommentSubmission
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&submission)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		errs := model.Validate(submission)
+		if len(errs) > 0 {
+			errMsgs := []string{}
+			for _, err := range errs {
+				errMsgs = append(errMsgs, err.Error())
+			}
+			msg := webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		var comment Comment
+		if submission.Website != "" {
+			comment = Comment{
+				ParentID:   submission.ParentID,
+				AuthorName: strings.TrimSpace(submission.AuthorName),
+				Body:       strings.TrimSpace(submission.Body),
+				Status:     StatusPending,
+			}
+			comment.HTML = renderMarkdown(comment.Body)
+		} else {
+			comment, err = model.Submit(vars["articleURI"], submission)
+			if err != nil {
+				if err.Error() == "no rows in result set" {
+					http.Error(w, "article not found", http.StatusNotFound)
+				} else if strings.Contains(err.Error(), "Invalid Request Body:") {
+					http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				} else {
+					fmt.Println(err.Error())
+					http.Error(w, "problem saving comment", http.StatusInternalServerError)
+				}
+				return
+			}
+		}
+
+		bytes, _ := json.Marshal(comment)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write(bytes)
+	}
+}
+
+func ModerateCommentHandler(model CommentDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["commentID"])
+		if err != nil {
+			http.Error(w, "comment not found", http.StatusNotFound)
+			return
+		}
+
+		var body struct {
+			Status string `json:"status"`
+		}
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if !IsStatus(body.Status) {
+			msg := webserverutils.NewRequestError(fmt.Sprintf("status must be one of %s", strings.Join(Statuses, ", ")))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		comment, err := model.Moderate(vars["articleURI"], id, body.Status)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "comment not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem moderating comment", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		bytes, _ := json.Marshal(comment)
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(bytes)
+	}
+}
@@ -0,0 +1,254 @@
+package comments
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+type MockCommentModel struct {
+	articles []string
+	comments *[]*Comment
+}
+
+func (model MockCommentModel) hasArticle(uri string) bool {
+	for _, article := range model.articles {
+		if article == uri {
+			return true
+		}
+	}
+	return false
+}
+func (model MockCommentModel) List(articleURI string, status string) ([]*Comment, error) {
+	if !model.hasArticle(articleURI) {
+		return nil, errors.New("no rows in result set")
+	}
+	comments := []*Comment{}
+	for _, c := range *model.comments {
+		if status == "" || c.Status == status {
+			copied := *c
+			comments = append(comments, &copied)
+		}
+	}
+	return comments, nil
+}
+func (model MockCommentModel) Submit(articleURI string, submission CommentSubmission) (Comment, error) {
+	if !model.hasArticle(articleURI) {
+		return Comment{}, errors.New("no rows in result set")
+	}
+	if submission.ParentID != nil {
+		approved := false
+		for _, c := range *model.comments {
+			approved = approved || (c.ID == *submission.ParentID && c.Status == StatusApproved)
+		}
+		if !approved {
+			return Comment{}, webserverutils.NewRequestError("parentId must be an approved comment on this article")
+		}
+	}
+	comment := Comment{
+		ID:         len(*model.comments) + 1,
+		ParentID:   submission.ParentID,
+		AuthorName: submission.AuthorName,
+		Body:       submission.Body,
+		Status:     StatusPending,
+	}
+	*model.comments = append(*model.comments, &comment)
+	return comment, nil
+}
+func (model MockCommentModel) Moderate(articleURI string, id int, status string) (Comment, error) {
+	for _, c := range *model.comments {
+		if c.ID == id && model.hasArticle(articleURI) {
+			c.Status = status
+			return *c, nil
+		}
+	}
+	return Comment{}, errors.New("no rows in result set")
+}
+func (model MockCommentModel) Validate(submission CommentSubmission) []error {
+	return (&CommentModel{}).Validate(submission)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func newMockModel() MockCommentModel {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	return MockCommentModel{
+		articles: []string{"some-article-1"},
+		comments: &[]*Comment{
+			{ID: 1, AuthorName: "Ann", Body: "First!", Status: StatusApproved, DateCreated: created},
+			{ID: 2, ParentID: intPtr(1), AuthorName: "Bob", Body: "Reply", Status: StatusApproved, DateCreated: created},
+			{ID: 3, AuthorName: "Spam", Body: "Buy now", Status: StatusRejected, DateCreated: created},
+			{ID: 4, ParentID: intPtr(3), AuthorName: "Cat", Body: "Orphaned", Status: StatusApproved, DateCreated: created},
+			{ID: 5, AuthorName: "Dan", Body: "Waiting", Status: StatusPending, DateCreated: created},
+		},
+	}
+}
+
+func TestListCommentsHandlerThreads(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/v1/articles/some-article-1/comments", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+
+	rr := httptest.NewRecorder()
+	ListCommentsHandler(newMockModel()).ServeHTTP(rr, req)
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d", 200, rr.Code)
+	}
+
+	var threads []Comment
+	json.Unmarshal(rr.Body.Bytes(), &threads)
+	if len(threads) != 1 || threads[0].ID != 1 {
+		t.Fatalf("expected only the approved top level comment but received %+v", threads)
+	}
+	if len(threads[0].Replies) != 1 || threads[0].Replies[0].ID != 2 {
+		t.Errorf("expected the reply nested under its parent but received %+v", threads[0].Replies)
+	}
+}
+
+func TestListCommentsHandlerStatus(t *testing.T) {
+	cases := []struct {
+		status       string
+		expectedCode int
+		expectedIDs  []int
+	}{
+		{"pending", 200, []int{5}},
+		{"rejected", 200, []int{3}},
+		{"all", 200, []int{1, 2, 3, 4, 5}},
+		{"deleted", 400, nil},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", "/api/v1/articles/some-article-1/comments?status="+c.status, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+
+		rr := httptest.NewRecorder()
+		ListCommentsHandler(newMockModel()).ServeHTTP(rr, req)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for '%s' but received %d", c.expectedCode, c.status, rr.Code)
+			continue
+		}
+		if c.expectedIDs == nil {
+			continue
+		}
+		var comments []Comment
+		json.Unmarshal(rr.Body.Bytes(), &comments)
+		if len(comments) != len(c.expectedIDs) {
+			t.Errorf("expected %d comments for '%s' but received %d", len(c.expectedIDs), c.status, len(comments))
+			continue
+		}
+		for idx, id := range c.expectedIDs {
+			if comments[idx].ID != id {
+				t.Errorf("expected comment %d at %d for '%s' but received %d", id, idx, c.status, comments[idx].ID)
+			}
+		}
+	}
+}
+
+func TestSubmitCommentHandler(t *testing.T) {
+	cases := []struct {
+		articleURI   string
+		body         string
+		expectedCode int
+		saved        bool
+	}{
+		{"some-article-1", `{"authorName": "Eve", "body": "Great read"}`, 202, true},
+		{"some-article-1", `{"parentId": 1, "authorName": "Eve", "body": "Agreed"}`, 202, true},
+		{"some-article-1", `{"parentId": 5, "authorName": "Eve", "body": "Replying to pending"}`, 422, false},
+		{"some-article-1", `{"authorName": "Bot", "body": "Cheap pills", "website": "http://spam.example"}`, 202, false},
+		{"some-article-1", `{"authorName": "", "body": ""}`, 422, false},
+		{"some-article-1", `{"authorName": "Eve", "body": "hi", "email": "eve@example.com"}`, 422, false},
+		{"some-article-2", `{"authorName": "Eve", "body": "Great read"}`, 404, false},
+	}
+	for _, c := range cases {
+		model := newMockModel()
+		req, err := http.NewRequest("POST", "/api/v1/articles/"+c.articleURI+"/comments", bytes.NewBufferString(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": c.articleURI})
+
+		rr := httptest.NewRecorder()
+		SubmitCommentHandler(model).ServeHTTP(rr, req)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d: %s", c.expectedCode, c.body, rr.Code, rr.Body.String())
+		}
+		if saved := len(*model.comments) == 6; saved != c.saved {
+			t.Errorf("expected saved to be %t for %s", c.saved, c.body)
+		}
+		if rr.Code == 202 {
+			var comment Comment
+			json.Unmarshal(rr.Body.Bytes(), &comment)
+			if comment.Status != StatusPending {
+				t.Errorf("expected a pending comment but received '%s'", comment.Status)
+			}
+		}
+	}
+}
+
+func TestSubmitCommentRateLimited(t *testing.T) {
+	model := newMockModel()
+	handler := middleware.RateLimitMiddleware(middleware.NewRateLimiter(2, time.Minute), SubmitCommentHandler(model))
+
+	expectedCodes := []int{202, 202, 429}
+	for _, expectedCode := range expectedCodes {
+		req, err := http.NewRequest("POST", "/api/v1/articles/some-article-1/comments", bytes.NewBufferString(`{"authorName": "Eve", "body": "Again"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.RemoteAddr = "203.0.113.7:5555"
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1"})
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != expectedCode {
+			t.Errorf("expected status code %d but received %d", expectedCode, rr.Code)
+		}
+		if rr.Code == 429 && rr.Header().Get("Retry-After") != "60" {
+			t.Errorf("expected Retry-After '60' but received '%s'", rr.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestModerateCommentHandler(t *testing.T) {
+	cases := []struct {
+		commentID    string
+		body         string
+		expectedCode int
+	}{
+		{"5", `{"status": "approved"}`, 200},
+		{"5", `{"status": "spam"}`, 422},
+		{"99", `{"status": "rejected"}`, 404},
+		{"abc", `{"status": "rejected"}`, 404},
+	}
+	for _, c := range cases {
+		model := newMockModel()
+		req, err := http.NewRequest("PUT", "/api/v1/articles/some-article-1/comments/"+c.commentID+"/status", bytes.NewBufferString(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"articleURI": "some-article-1", "commentID": c.commentID})
+
+		rr := httptest.NewRecorder()
+		ModerateCommentHandler(model).ServeHTTP(rr, req)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s %s but received %d", c.expectedCode, c.commentID, c.body, rr.Code)
+		}
+		if rr.Code == 200 && (*model.comments)[4].Status != StatusApproved {
+			t.Errorf("expected comment 5 to be approved")
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package comments
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Comments get a small subset of markdown: paragraphs, emphasis, code,
+// quotes, lists and links. Everything is HTML escaped before any markup is
+// added, so the only tags in the output are the ones added here.
+
+var (
+	commentFencePattern = regexp.MustCompile("^\\s{0,3}```")
+	listItemPattern     = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	quotePattern        = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	codeSpanPattern     = regexp.MustCompile("`([^`]+)`")
+	strongPattern       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emPattern           = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	commentLinkPattern  = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+var allowedLinkSchemes = []string{"http", "https", "mailto"}
+
+func renderMarkdown(body string) string {
+	var out strings.Builder
+	var paragraph, quote, list, code []string
+	inFence := false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderLines(paragraph) + "</p>\n")
+			paragraph = nil
+		}
+		if len(quote) > 0 {
+			out.WriteString("<blockquote><p>" + renderLines(quote) + "</p></blockquote>\n")
+			quote = nil
+		}
+		if len(list) > 0 {
+			out.WriteString("<ul>\n")
+			for _, item := range list {
+				out.WriteString("<li>" + renderInline(item) + "</li>\n")
+			}
+			out.WriteString("</ul>\n")
+			list = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if commentFencePattern.MatchString(line) {
+			if inFence {
+				out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+				code = nil
+			} else {
+				flush()
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			code = append(code, line)
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+		} else if match := quotePattern.FindStringSubmatch(line); match != nil {
+			if len(quote) == 0 {
+				flush()
+			}
+			quote = append(quote, match[1])
+		} else if match := listItemPattern.FindStringSubmatch(line); match != nil {
+			if len(list) == 0 {
+				flush()
+			}
+			list = append(list, match[1])
+		} else if len(list) > 0 {
+			// a wrapped list item
+			list[len(list)-1] += " " + strings.TrimSpace(line)
+		} else {
+			if len(quote) > 0 {
+				flush()
+			}
+			paragraph = append(paragraph, line)
+		}
+	}
+	// an unclosed fence still renders as code
+	if inFence {
+		out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+	}
+	flush()
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func renderLines(lines []string) string {
+	rendered := make([]string, len(lines))
+	for idx, line := range lines {
+		rendered[idx] = renderInline(strings.TrimSpace(line))
+	}
+	return strings.Join(rendered, "<br>\n")
+}
+
+// Escapes the text then applies inline markup, leaving code spans untouched
+func renderInline(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(renderEmphasis(text[last:loc[0]]))
+		out.WriteString("<code>" + html.EscapeString(text[loc[2]:loc[3]]) + "</code>")
+		last = loc[1]
+	}
+	out.WriteString(renderEmphasis(text[last:]))
+	return out.String()
+}
+
+// Links are pulled out first so emphasis can't reach into an href
+func renderEmphasis(text string) string {
+	text = html.EscapeString(text)
+	var out strings.Builder
+	last := 0
+	for _, loc := range commentLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(applyEmphasis(text[last:loc[0]]))
+		label := applyEmphasis(text[loc[2]:loc[3]])
+		href := html.UnescapeString(text[loc[4]:loc[5]])
+		if isSafeLink(href) {
+			out.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow ugc noopener">` + label + `</a>`)
+		} else {
+			out.WriteString(label)
+		}
+		last = loc[1]
+	}
+	out.WriteString(applyEmphasis(text[last:]))
+	return out.String()
+}
+
+func applyEmphasis(text string) string {
+	text = strongPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	return emPattern.ReplaceAllString(text, "<em>$1$2</em>")
+}
+
+func isSafeLink(href string) bool {
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	for _, allowed := range allowedLinkSchemes {
+		if scheme == allowed {
+			return true
+		}
+	}
+	return false
+}
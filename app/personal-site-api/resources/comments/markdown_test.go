@@ -0,0 +1,56 @@
+package comments
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		{"Nice **post**, *thanks*!", "<p>Nice <strong>post</strong>, <em>thanks</em>!</p>"},
+		{"line one\nline two\n\nnext", "<p>line one<br>\nline two</p>\n<p>next</p>"},
+		{"use `a < b` here", "<p>use <code>a &lt; b</code> here</p>"},
+		{"> quoted\n\nreply", "<blockquote><p>quoted</p></blockquote>\n<p>reply</p>"},
+		{"- one\n- two", "<ul>\n<li>one</li>\n<li>two</li>\n</ul>"},
+		{"```\n<b>raw</b>\n```", "<pre><code>&lt;b&gt;raw&lt;/b&gt;</code></pre>"},
+		{"see [the docs](https://go.dev/doc?a=1&b=2)", `<p>see <a href="https://go.dev/doc?a=1&amp;b=2" rel="nofollow ugc noopener">the docs</a></p>`},
+		{"snake_case_name stays", "<p>snake_case_name stays</p>"},
+	}
+	for _, c := range cases {
+		html := renderMarkdown(c.body)
+		if html != c.expected {
+			t.Errorf("expected %q for %q but received %q", c.expected, c.body, html)
+		}
+	}
+}
+
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	allowedTags := map[string]bool{
+		"p": true, "br": true, "strong": true, "em": true, "code": true, "pre": true,
+		"blockquote": true, "ul": true, "li": true, "a": true,
+	}
+	tagPattern := regexp.MustCompile(`<\/?([a-zA-Z]+)([^>]*)>`)
+	bodies := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"[click](javascript:alert(1))",
+		"[click](JaVaScRiPt:alert(1))",
+		`[click](https://example.com" onclick="alert(1))`,
+		"**<iframe src=//evil>**",
+	}
+	for _, body := range bodies {
+		html := renderMarkdown(body)
+		for _, match := range tagPattern.FindAllStringSubmatch(html, -1) {
+			if !allowedTags[match[1]] {
+				t.Errorf("unexpected <%s> tag rendering %q: %q", match[1], body, html)
+			}
+			if attrs := match[2]; attrs != "" && !strings.HasPrefix(attrs, ` href="https://`) {
+				t.Errorf("unexpected attributes rendering %q: %q", body, html)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+var Statuses = []string{StatusPending, StatusApproved, StatusRejected}
+
+const (
+	maxAuthorNameLength = 100
+	maxBodyLength       = 5000
+)
+
+// A reader's comment on an article. Body is the markdown they wrote and HTML
+// is its sanitized rendering. Replies are only filled in for threaded listings.
+type Comment struct {
+	ID          int        `json:"id"`
+	ParentID    *int       `json:"parentId"`
+	AuthorName  string     `json:"authorName"`
+	Body        string     `json:"body"`
+	HTML        string     `json:"html"`
+	Status      string     `json:"status"`
+	DateCreated time.Time  `json:"dateCreated"`
+	Replies     []*Comment `json:"replies,omitempty"`
+}
+
+// What a reader sends to leave a comment. Website is a honeypot, hidden from
+// people by the frontend, so anything in it came from a bot.
+type CommentSubmission struct {
+	ParentID   *int   `json:"parentId"`
+	AuthorName string `json:"authorName"`
+	Body       string `json:"body"`
+	Website    string `json:"website"`
+}
+
+// An interface to refresent the Model (for mocking in test)
+type CommentDataAccessLayer interface {
+	List(articleURI string, status string) (comments []*Comment, err error)
+	Submit(articleURI string, submission CommentSubmission) (comment Comment, err error)
+	Moderate(articleURI string, id int, status string) (comment Comment, err error)
+	Validate(submission CommentSubmission) (errs []error)
+}
+
+// The Model with Database Implementation
+type CommentModel struct {
+	DB *pgx.Conn
+}
+
+// Comments with the given status in the order they were written, or every
+// comment when status is empty
+func (model *CommentModel) List(articleURI string, status string) (comments []*Comment, err error) {
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return comments, err
+	}
+
+	stmt := `
+		SELECT id, parent_id, author_name, body_md, status, dt_created
+		FROM article_comments
+		WHERE article_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY dt_created, id;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, articleID, status)
+	if err != nil {
+		return comments, err
+	}
+	defer rows.Close()
+
+	comments = []*Comment{}
+	for rows.Next() {
+		var c Comment
+		err = rows.Scan(&c.ID, &c.ParentID, &c.AuthorName, &c.Body, &c.Status, &c.DateCreated)
+		if err != nil {
+			return comments, err
+		}
+		c.HTML = renderMarkdown(c.Body)
+		comments = append(comments, &c)
+	}
+	return comments, rows.Err()
+}
+
+// Saves a new comment as pending. Replies must be to an approved comment on
+// the same article.
+func (model *CommentModel) Submit(articleURI string, submission CommentSubmission) (comment Comment, err error) {
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return comment, err
+	}
+
+	if submission.ParentID != nil {
+		var parentStatus string
+		err = model.DB.QueryRow(
+			context.Background(),
+			"SELECT status FROM article_comments WHERE id = $1 AND article_id = $2;",
+			*submission.ParentID, articleID,
+		).Scan(&parentStatus)
+		if err == pgx.ErrNoRows || (err == nil && parentStatus != StatusApproved) {
+			return comment, webserverutils.NewRequestError("parentId must be an approved comment on this article")
+		}
+		if err != nil {
+			return comment, err
+		}
+	}
+
+	comment = Comment{
+		ParentID:   submission.ParentID,
+		AuthorName: strings.TrimSpace(submission.AuthorName),
+		Body:       strings.TrimSpace(submission.Body),
+		Status:     StatusPending,
+	}
+	stmt := `
+		INSERT INTO article_comments (article_id, parent_id, author_name, body_md, status, dt_created)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, dt_created
+	`
+	err = model.DB.QueryRow(
+		context.Background(),
+		stmt,
+		articleID, comment.ParentID, comment.AuthorName, comment.Body, comment.Status, time.Now(),
+	).Scan(&comment.ID, &comment.DateCreated)
+	if err != nil {
+		return comment, database.TranslateError(err)
+	}
+	comment.HTML = renderMarkdown(comment.Body)
+	return comment, nil
+}
+
+func (model *CommentModel) Moderate(articleURI string, id int, status string) (comment Comment, err error) {
+	stmt := `
+		UPDATE article_comments
+		SET status = $3, dt_moderated = $4
+		FROM articles
+		WHERE articles.id = article_comments.article_id AND articles.uri = $1 AND article_comments.id = $2
+		RETURNING article_comments.id, parent_id, author_name, body_md, status, article_comments.dt_created
+	`
+	err = model.DB.QueryRow(context.Background(), stmt, articleURI, id, status, time.Now()).Scan(
+		&comment.ID, &comment.ParentID, &comment.AuthorName, &comment.Body, &comment.Status, &comment.DateCreated,
+	)
+	if err != nil {
+		return comment, err
+	}
+	comment.HTML = renderMarkdown(comment.Body)
+	return comment, nil
+}
+
+func (model *CommentModel) Validate(submission CommentSubmission) (errs []error) {
+	errs = []error{}
+	name := strings.TrimSpace(submission.AuthorName)
+	if name == "" {
+		errs = append(errs, errors.New("missing authorName"))
+	} else if utf8.RuneCountInString(name) > maxAuthorNameLength {
+		errs = append(errs, fmt.Errorf("authorName can be at most %d characters", maxAuthorNameLength))
+	}
+	body := strings.TrimSpace(submission.Body)
+	if body == "" {
+		errs = append(errs, errors.New("missing body"))
+	} else if utf8.RuneCountInString(body) > maxBodyLength {
+		errs = append(errs, fmt.Errorf("body can be at most %d characters", maxBodyLength))
+	}
+	return errs
+}
+
+func IsStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Nests approved comments under their parents. Replies to comments that
+// aren't in the list, such as ones later rejected, are dropped with them.
+func BuildThreads(comments []*Comment) []*Comment {
+	byID := map[int]*Comment{}
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+	threads := []*Comment{}
+	for _, c := range comments {
+		if c.ParentID == nil {
+			threads = append(threads, c)
+		} else if parent, ok := byID[*c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+	return threads
+}
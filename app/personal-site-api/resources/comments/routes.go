@@ -0,0 +1,18 @@
+package comments
+
+import (
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+)
+
+// Each address can leave a handful of comments every ten minutes
+var submitLimiter = middleware.NewRateLimiter(5, 10*time.Minute)
+
+func InitializeRoutes(router *mux.Router, model CommentDataAccessLayer) {
+	router.HandleFunc("", middleware.AuthMiddleware(ListCommentsHandler(model))).Methods("GET").Queries("status", "{status}")
+	router.HandleFunc("", ListCommentsHandler(model)).Methods("GET")
+	router.HandleFunc("", middleware.RateLimitMiddleware(submitLimiter, SubmitCommentHandler(model))).Methods("POST")
+	router.HandleFunc("/{commentID}/status", middleware.AuthMiddleware(ModerateCommentHandler(model))).Methods("PUT")
+}
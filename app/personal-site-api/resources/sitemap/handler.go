@@ -0,0 +1,147 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/articles"
+)
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// The most URLs the protocol allows in one sitemap, past which /sitemap.xml
+// becomes an index of numbered sitemaps
+var urlsPerSitemap = 50000
+
+// The part of the article model the sitemap is built from
+type ArticleSource interface {
+	SitemapEntries(offset int, limit int) (entries []articles.SitemapEntry, err error)
+	SitemapPages(size int) (pages []time.Time, err error)
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// Lists every article, or an index of numbered sitemaps once there are too
+// many for one
+func SitemapHandler(model ArticleSource, site cfg.SiteConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the index only needs to know when each page changed, not what's on it
+		pages, err := model.SitemapPages(urlsPerSitemap)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "problem building sitemap", http.StatusInternalServerError)
+			return
+		}
+		if len(pages) <= 1 {
+			entries, err := model.SitemapEntries(0, urlsPerSitemap)
+			if err != nil {
+				fmt.Println(err.Error())
+				http.Error(w, "problem building sitemap", http.StatusInternalServerError)
+				return
+			}
+			writeXML(w, buildURLSet(entries, site))
+			return
+		}
+
+		index := sitemapIndex{Xmlns: sitemapNamespace}
+		for idx, lastModified := range pages {
+			index.Sitemaps = append(index.Sitemaps, sitemapRef{
+				Loc:     fmt.Sprintf("%s/sitemap-%d.xml", site.BaseURL, idx+1),
+				LastMod: lastModified.UTC().Format(time.RFC3339),
+			})
+		}
+		writeXML(w, index)
+	}
+}
+
+// One of the numbered sitemaps listed by the index, counting from 1
+func SitemapPageHandler(model ArticleSource, site cfg.SiteConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		page, err := strconv.Atoi(vars["page"])
+		if err != nil || page < 1 {
+			http.Error(w, "sitemap not found", http.StatusNotFound)
+			return
+		}
+		entries, err := model.SitemapEntries((page-1)*urlsPerSitemap, urlsPerSitemap)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "problem building sitemap", http.StatusInternalServerError)
+			return
+		}
+		if len(entries) == 0 {
+			http.Error(w, "sitemap not found", http.StatusNotFound)
+			return
+		}
+		writeXML(w, buildURLSet(entries, site))
+	}
+}
+
+func buildURLSet(entries []articles.SitemapEntry, site cfg.SiteConfig) urlSet {
+	set := urlSet{Xmlns: sitemapNamespace, URLs: []sitemapURL{}}
+	for _, entry := range entries {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     site.ArticleURL(entry.URI),
+			LastMod: entry.LastModified.UTC().Format(time.RFC3339),
+		})
+	}
+	return set
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, "internal error building response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+// Every crawler gets the configured rules, followed by where the sitemap is
+func RobotsHandler(site cfg.SiteConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lines := []string{"User-agent: *"}
+		for _, path := range site.Robots.Allow {
+			lines = append(lines, "Allow: "+path)
+		}
+		for _, path := range site.Robots.Disallow {
+			lines = append(lines, "Disallow: "+path)
+		}
+		if len(site.Robots.Allow) == 0 && len(site.Robots.Disallow) == 0 {
+			lines = append(lines, "Disallow:")
+		}
+		if site.BaseURL != "" {
+			lines = append(lines, "", "Sitemap: "+site.BaseURL+"/sitemap.xml")
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	}
+}
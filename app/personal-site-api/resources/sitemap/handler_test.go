@@ -0,0 +1,165 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/articles"
+)
+
+var testSite = cfg.SiteConfig{
+	BaseURL:        "https://example.com",
+	ArticleBaseURL: "https://example.com/articles/",
+}
+
+type MockArticleSource struct {
+	entries []articles.SitemapEntry
+}
+
+func (model MockArticleSource) SitemapEntries(offset int, limit int) ([]articles.SitemapEntry, error) {
+	if offset >= len(model.entries) {
+		return []articles.SitemapEntry{}, nil
+	}
+	end := offset + limit
+	if end > len(model.entries) {
+		end = len(model.entries)
+	}
+	return model.entries[offset:end], nil
+}
+
+func (model MockArticleSource) SitemapPages(size int) ([]time.Time, error) {
+	pages := []time.Time{}
+	for idx, entry := range model.entries {
+		if idx%size == 0 {
+			pages = append(pages, entry.LastModified)
+		} else if entry.LastModified.After(pages[len(pages)-1]) {
+			pages[len(pages)-1] = entry.LastModified
+		}
+	}
+	return pages, nil
+}
+
+func mockEntries(n int) MockArticleSource {
+	source := MockArticleSource{}
+	for i := 1; i <= n; i++ {
+		source.entries = append(source.entries, articles.SitemapEntry{
+			URI:          fmt.Sprintf("some-article-%d", i),
+			LastModified: time.Date(2022, 1, i, 3, 4, 5, 0, time.FixedZone("EST", -5*60*60)),
+		})
+	}
+	return source
+}
+
+func serve(t *testing.T, handler http.HandlerFunc, vars map[string]string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", "/sitemap.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, mux.SetURLVars(req, vars))
+	return rr
+}
+
+func TestSitemapHandler(t *testing.T) {
+	rr := serve(t, SitemapHandler(mockEntries(2), testSite), nil)
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d", 200, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/xml; charset=utf-8" {
+		t.Errorf("expected an xml content type but received '%s'", contentType)
+	}
+
+	var set urlSet
+	err := xml.Unmarshal(rr.Body.Bytes(), &set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []sitemapURL{
+		{Loc: "https://example.com/articles/some-article-1", LastMod: "2022-01-01T08:04:05Z"},
+		{Loc: "https://example.com/articles/some-article-2", LastMod: "2022-01-02T08:04:05Z"},
+	}
+	if len(set.URLs) != len(expected) {
+		t.Fatalf("expected %d urls but received %d", len(expected), len(set.URLs))
+	}
+	for idx, url := range expected {
+		if set.URLs[idx] != url {
+			t.Errorf("expected %+v but received %+v", url, set.URLs[idx])
+		}
+	}
+}
+
+func TestSitemapHandlerSplitsIntoIndex(t *testing.T) {
+	defer func(n int) { urlsPerSitemap = n }(urlsPerSitemap)
+	urlsPerSitemap = 2
+	source := mockEntries(5)
+
+	rr := serve(t, SitemapHandler(source, testSite), nil)
+	var index sitemapIndex
+	err := xml.Unmarshal(rr.Body.Bytes(), &index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.XMLName.Local != "sitemapindex" || len(index.Sitemaps) != 3 {
+		t.Fatalf("expected an index of 3 sitemaps but received %s", rr.Body.String())
+	}
+	if index.Sitemaps[2].Loc != "https://example.com/sitemap-3.xml" {
+		t.Errorf("expected the last sitemap at '%s' but received '%s'", "https://example.com/sitemap-3.xml", index.Sitemaps[2].Loc)
+	}
+	if index.Sitemaps[0].LastMod != "2022-01-02T08:04:05Z" || index.Sitemaps[2].LastMod != "2022-01-05T08:04:05Z" {
+		t.Errorf("expected each sitemap to carry its latest change but received %+v", index.Sitemaps)
+	}
+
+	cases := []struct {
+		page         string
+		expectedCode int
+		expectedURLs int
+	}{
+		{"1", 200, 2},
+		{"3", 200, 1},
+		{"4", 404, 0},
+		{"0", 404, 0},
+	}
+	for _, c := range cases {
+		rr := serve(t, SitemapPageHandler(source, testSite), map[string]string{"page": c.page})
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for page %s but received %d", c.expectedCode, c.page, rr.Code)
+			continue
+		}
+		if rr.Code != 200 {
+			continue
+		}
+		var set urlSet
+		xml.Unmarshal(rr.Body.Bytes(), &set)
+		if len(set.URLs) != c.expectedURLs {
+			t.Errorf("expected %d urls on page %s but received %d", c.expectedURLs, c.page, len(set.URLs))
+		}
+	}
+}
+
+func TestRobotsHandler(t *testing.T) {
+	cases := []struct {
+		site     cfg.SiteConfig
+		expected string
+	}{
+		{cfg.SiteConfig{}, "User-agent: *\nDisallow:\n"},
+		{
+			cfg.SiteConfig{
+				BaseURL: "https://example.com",
+				Robots:  cfg.RobotsConfig{Allow: []string{"/drafts/public"}, Disallow: []string{"/drafts", "/api"}},
+			},
+			"User-agent: *\nAllow: /drafts/public\nDisallow: /drafts\nDisallow: /api\n\nSitemap: https://example.com/sitemap.xml\n",
+		},
+	}
+	for _, c := range cases {
+		rr := serve(t, RobotsHandler(c.site), nil)
+		if rr.Body.String() != c.expected {
+			t.Errorf("expected %q but received %q", c.expected, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package sitemap
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
+)
+
+// Registered at the root. The sitemap needs absolute article URLs, so it's
+// only served once the site's base URL is configured.
+func InitializeRoutes(router *mux.Router, model ArticleSource, site cfg.SiteConfig) {
+	router.HandleFunc("/robots.txt", RobotsHandler(site)).Methods("GET")
+	if site.BaseURL == "" {
+		return
+	}
+	router.HandleFunc("/sitemap.xml", SitemapHandler(model, site)).Methods("GET")
+	router.HandleFunc("/sitemap-{page:[0-9]+}.xml", SitemapPageHandler(model, site)).Methods("GET")
+}
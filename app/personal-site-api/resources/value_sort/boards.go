@@ -0,0 +1,198 @@
+package valuesort
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+// There are no accounts, so whoever sorts boards picks a key of their own
+// (kept by the client) and sends it with each request. Boards made without
+// one don't belong to anybody.
+const OwnerHeader = "X-Board-Owner"
+
+const maxOwnerLength = 100
+
+var ErrNotOwner = errors.New("the board belongs to someone else")
+
+type BoardSummary struct {
+	Name         string    `json:"name"`
+	Deck         string    `json:"deck,omitempty"`
+	Locale       string    `json:"locale,omitempty"`
+	Version      int64     `json:"version"`
+	DateCreated  time.Time `json:"dateCreated"`
+	DateModified time.Time `json:"dateModified"`
+}
+
+// The owner's boards, most recently changed first
+func (model *ValueSortBoardModel) List(owner string) (boards []BoardSummary, err error) {
+	rows, err := model.DB.Query(
+		context.Background(),
+		`SELECT name, coalesce(deck_name, ''), coalesce(locale, ''), version, date_created, date_modified
+		FROM value_sort_boards
+		WHERE owner = $1
+		ORDER BY date_modified DESC, name`,
+		owner,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	boards = []BoardSummary{}
+	for rows.Next() {
+		var board BoardSummary
+		err = rows.Scan(&board.Name, &board.Deck, &board.Locale, &board.Version, &board.DateCreated, &board.DateModified)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+	return boards, rows.Err()
+}
+
+// Deletes the board along with its cards, columns, history, snapshots and
+// rounds. Anyone watching it is told it's gone.
+func (model *ValueSortBoardModel) Delete(boardName string, owner string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM value_sort_boards WHERE name = $1`, boardName)
+	if err != nil {
+		return err
+	}
+	err = notify(ctx, tx, BoardEvent{Type: EventDeleted, Board: boardName})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Gives the board a new name, taking everything on it along
+func (model *ValueSortBoardModel) Rename(boardName string, newName string, owner string) (err error) {
+	if newName == boardName {
+		return webserverutils.NewRequestError("new name matches the current name")
+	}
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return err
+	}
+	var version int64
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE value_sort_boards SET name = $2, version = version + 1, date_modified = now() WHERE name = $1 RETURNING version`,
+		boardName, newName,
+	).Scan(&version)
+	if err != nil {
+		return database.TranslateError(err)
+	}
+	// snapshots keep a copy of the board, name and all
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE value_sort_snapshots SET state = jsonb_set(state, '{name}', to_jsonb($1::text)) WHERE board_name = $1`,
+		newName,
+	)
+	if err != nil {
+		return err
+	}
+	err = notify(ctx, tx, BoardEvent{Type: EventRenamed, Board: boardName, Version: version, RenamedTo: newName})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Copies the board as it is now, columns, rounds and all, to start another
+// from. The copy's history starts from here and its snapshots aren't copied.
+func (model *ValueSortBoardModel) Clone(boardName string, newName string, owner string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var name string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO value_sort_boards (name, deck_name, locale, owner, current_round)
+		SELECT $2, deck_name, locale, nullif($3, ''), current_round FROM value_sort_boards WHERE name = $1
+		RETURNING name`,
+		boardName, newName, owner,
+	).Scan(&name)
+	if err != nil {
+		return database.TranslateError(err)
+	}
+
+	stmts := []string{
+		`INSERT INTO value_sort_columns (board_name, title, position, card_limit)
+		SELECT $2, title, position, card_limit FROM value_sort_columns WHERE board_name = $1`,
+		`INSERT INTO value_sort_cards (board_name, card_body, card_details, column_name, position)
+		SELECT $2, card_body, card_details, column_name, position FROM value_sort_cards WHERE board_name = $1`,
+		`INSERT INTO value_sort_moves (board_name, card_body, card_details, to_column, position)
+		SELECT $2, card_body, card_details, column_name, position FROM value_sort_cards WHERE board_name = $1`,
+		`INSERT INTO value_sort_rounds (board_name, number, title)
+		SELECT $2, number, title FROM value_sort_rounds WHERE board_name = $1`,
+		`INSERT INTO value_sort_round_columns (board_name, round_number, column_name, capacity, required)
+		SELECT $2, round_number, column_name, capacity, required FROM value_sort_round_columns WHERE board_name = $1`,
+	}
+	for _, stmt := range stmts {
+		_, err = tx.Exec(ctx, stmt, boardName, newName)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Locks the board for the rest of the transaction, refusing anyone but its
+// owner. Boards nobody owns can be changed by anyone, as they always could.
+func checkOwner(ctx context.Context, tx pgx.Tx, boardName string, owner string) error {
+	var boardOwner *string
+	err := tx.QueryRow(ctx, `SELECT owner FROM value_sort_boards WHERE name = $1 FOR UPDATE`, boardName).Scan(&boardOwner)
+	if err != nil {
+		return err
+	}
+	if boardOwner != nil && *boardOwner != owner {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func ValidateBoardName(name string) []error {
+	errs := []error{}
+	if strings.TrimSpace(name) == "" {
+		errs = append(errs, errors.New("missing name"))
+	} else if strings.Contains(name, "/") {
+		errs = append(errs, errors.New("name cannot contain '/'"))
+	}
+	return errs
+}
+
+func ValidateOwner(owner string) []error {
+	errs := []error{}
+	if len(owner) > maxOwnerLength {
+		errs = append(errs, fmt.Errorf("%s can be at most %d characters", OwnerHeader, maxOwnerLength))
+	}
+	return errs
+}
@@ -0,0 +1,249 @@
+package valuesort
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type MockBoardsModel struct {
+	MockBoardModel
+	owners map[string]string
+}
+
+func (model MockBoardsModel) List(owner string) ([]BoardSummary, error) {
+	boards := []BoardSummary{}
+	for name, boardOwner := range model.owners {
+		if boardOwner == owner {
+			boards = append(boards, BoardSummary{Name: name, DateCreated: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)})
+		}
+	}
+	return boards, nil
+}
+func (model MockBoardsModel) checkOwner(boardName string, owner string) error {
+	if _, ok := model.boards[boardName]; !ok {
+		return errors.New("no rows in result set")
+	}
+	if boardOwner, ok := model.owners[boardName]; ok && boardOwner != owner {
+		return ErrNotOwner
+	}
+	return nil
+}
+func (model MockBoardsModel) Delete(boardName string, owner string) error {
+	err := model.checkOwner(boardName, owner)
+	if err != nil {
+		return err
+	}
+	delete(model.boards, boardName)
+	return nil
+}
+func (model MockBoardsModel) Rename(boardName string, newName string, owner string) error {
+	err := model.checkOwner(boardName, owner)
+	if err != nil {
+		return err
+	}
+	if _, ok := model.boards[newName]; ok {
+		return errors.New("Invalid Request Body: Unique constraint violated.")
+	}
+	board := model.boards[boardName]
+	board.Name = newName
+	model.boards[newName] = board
+	delete(model.boards, boardName)
+	return nil
+}
+func (model MockBoardsModel) Clone(boardName string, newName string, owner string) error {
+	board, ok := model.boards[boardName]
+	if !ok {
+		return errors.New("no rows in result set")
+	}
+	if _, ok := model.boards[newName]; ok {
+		return errors.New("Invalid Request Body: Unique constraint violated.")
+	}
+	board.Name = newName
+	model.boards[newName] = board
+	model.owners[newName] = owner
+	return nil
+}
+
+func (model MockBoardsModel) Upsert(board ValueSortBoard, owner string) error {
+	err := model.checkOwner(board.Name, owner)
+	if err != nil {
+		return err
+	}
+	return model.MockBoardModel.Upsert(board, owner)
+}
+func (model MockBoardsModel) SetColumns(boardName string, columns []ColumnDefinition, owner string) error {
+	err := model.checkOwner(boardName, owner)
+	if err != nil {
+		return err
+	}
+	return model.MockBoardModel.SetColumns(boardName, columns, owner)
+}
+
+func mockOwnedBoards() MockBoardsModel {
+	model := MockBoardsModel{MockBoardModel: mockBoards(), owners: map[string]string{"some-board": "owner-key"}}
+	model.boards["other-board"] = ValueSortBoard{Name: "other-board", Columns: []ValueSortColumn{}}
+	return model
+}
+
+func boardRequest(t *testing.T, handler http.HandlerFunc, method string, path string, boardName string, owner string, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "" {
+		req.Header.Set(OwnerHeader, owner)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": boardName}))
+	return rr
+}
+
+func TestListBoardsHandler(t *testing.T) {
+	model := mockOwnedBoards()
+	rr := boardRequest(t, ListBoardsHandler(model), "GET", "/api/v1/value-sort/boards", "", "owner-key", "")
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d - %s", 200, rr.Code, rr.Body.String())
+	}
+	var boards []BoardSummary
+	err := json.Unmarshal(rr.Body.Bytes(), &boards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 1 || boards[0].Name != "some-board" {
+		t.Errorf("expected only the owner's board but received %+v", boards)
+	}
+
+	if rr := boardRequest(t, ListBoardsHandler(model), "GET", "/api/v1/value-sort/boards", "", "", ""); rr.Code != 422 {
+		t.Errorf("expected status code %d without an owner but received %d", 422, rr.Code)
+	}
+}
+
+func TestDeleteBoardHandler(t *testing.T) {
+	cases := []struct {
+		boardName    string
+		owner        string
+		expectedCode int
+	}{
+		{"some-board", "someone-else", 403},
+		{"some-board", "", 403},
+		{"some-board", "owner-key", 204},
+		{"other-board", "", 204},
+		{"missing-board", "owner-key", 404},
+	}
+	model := mockOwnedBoards()
+	for _, c := range cases {
+		rr := boardRequest(t, DeleteBoardHandler(model), "DELETE", "/api/v1/value-sort/boards/"+c.boardName, c.boardName, c.owner, "")
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d deleting %s as %q but received %d", c.expectedCode, c.boardName, c.owner, rr.Code)
+		}
+	}
+	if len(model.boards) != 0 {
+		t.Errorf("expected every board to be deleted but %d remain", len(model.boards))
+	}
+}
+
+func TestRenameBoardHandler(t *testing.T) {
+	model := mockOwnedBoards()
+	rr := boardRequest(t, RenameBoardHandler(model), "POST", "/api/v1/value-sort/boards/some-board/rename", "some-board", "owner-key", `{"name": "renamed-board"}`)
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d - %s", 200, rr.Code, rr.Body.String())
+	}
+	if location := rr.Header().Get("Location"); location != "/api/v1/value-sort/boards/renamed-board" {
+		t.Errorf("expected the new location but received %s", location)
+	}
+	if _, ok := model.boards["renamed-board"]; !ok {
+		t.Error("expected the board under its new name")
+	}
+
+	cases := []struct {
+		boardName    string
+		owner        string
+		body         string
+		expectedCode int
+	}{
+		{"other-board", "", `{"name": "renamed-board"}`, 422},
+		{"other-board", "", `{"name": "a/b"}`, 422},
+		{"other-board", "", `{"name": ""}`, 422},
+		{"missing-board", "", `{"name": "new-board"}`, 404},
+	}
+	for _, c := range cases {
+		rr := boardRequest(t, RenameBoardHandler(model), "POST", "/api/v1/value-sort/boards/"+c.boardName+"/rename", c.boardName, c.owner, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d renaming %s to %s but received %d", c.expectedCode, c.boardName, c.body, rr.Code)
+		}
+	}
+}
+
+func TestCloneBoardHandler(t *testing.T) {
+	model := mockOwnedBoards()
+	rr := boardRequest(t, CloneBoardHandler(model), "POST", "/api/v1/value-sort/boards/some-board/clone", "some-board", "another-owner", `{"name": "copied-board"}`)
+	if rr.Code != 201 {
+		t.Fatalf("expected status code %d but received %d - %s", 201, rr.Code, rr.Body.String())
+	}
+	var board ValueSortBoard
+	err := json.Unmarshal(rr.Body.Bytes(), &board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Name != "copied-board" || len(board.Columns) != 2 || len(board.Columns[0].Cards) != 1 {
+		t.Errorf("expected a copy of the board but received %+v", board)
+	}
+	if model.owners["copied-board"] != "another-owner" {
+		t.Errorf("expected the copy to belong to whoever made it but it belongs to %q", model.owners["copied-board"])
+	}
+	if _, ok := model.boards["some-board"]; !ok {
+		t.Error("expected the original board to be left alone")
+	}
+
+	cases := []struct {
+		boardName    string
+		owner        string
+		body         string
+		expectedCode int
+	}{
+		{"some-board", "", `{"name": "copied-board"}`, 422},
+		{"some-board", "", `{"name": " "}`, 422},
+		{"some-board", strings.Repeat("k", 101), `{"name": "another-copy"}`, 422},
+		{"missing-board", "", `{"name": "another-copy"}`, 404},
+	}
+	for _, c := range cases {
+		rr := boardRequest(t, CloneBoardHandler(model), "POST", "/api/v1/value-sort/boards/"+c.boardName+"/clone", c.boardName, c.owner, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d copying %s to %s but received %d", c.expectedCode, c.boardName, c.body, rr.Code)
+		}
+	}
+}
+
+func TestBoardWritesAreOwnerGuarded(t *testing.T) {
+	model := mockOwnedBoards()
+	board := `{"columns": [{"title": "Unsorted", "cards": []}]}`
+	columns := `[{"title": "Unsorted", "order": 0}]`
+	cases := []struct {
+		boardName    string
+		owner        string
+		expectedCode int
+	}{
+		{"some-board", "owner-key", 200},
+		{"some-board", "someone-else", 403},
+		{"some-board", "", 403},
+		{"other-board", "", 200},
+		{"other-board", "anyone", 200},
+	}
+	for _, c := range cases {
+		path := "/api/v1/value-sort/boards/" + c.boardName
+		if rr := boardRequest(t, UpdateBoardHandler(model), "PUT", path, c.boardName, c.owner, board); rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d updating %s as %q but received %d", c.expectedCode, c.boardName, c.owner, rr.Code)
+		}
+		if rr := boardRequest(t, UpdateColumnsHandler(model), "PUT", path+"/columns", c.boardName, c.owner, columns); rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d changing the columns of %s as %q but received %d", c.expectedCode, c.boardName, c.owner, rr.Code)
+		}
+	}
+}
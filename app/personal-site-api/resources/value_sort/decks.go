@@ -0,0 +1,401 @@
+package valuesort
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+)
+
+// What a card says in one language
+type CardText struct {
+	Body    string `json:"body"`
+	Details string `json:"details"`
+}
+
+// A card in a deck, with its text in each locale it's been translated to
+type DeckCard struct {
+	Key  string              `json:"key"`
+	Text map[string]CardText `json:"text"`
+}
+
+type Deck struct {
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	DefaultLocale string     `json:"defaultLocale"`
+	Cards         []DeckCard `json:"cards"`
+}
+
+type DeckSummary struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	DefaultLocale string   `json:"defaultLocale"`
+	Locales       []string `json:"locales"`
+	CardCount     int      `json:"cardCount"`
+}
+
+// An interface to represent the deck Model (for mocking in test)
+type DeckDataAccessLayer interface {
+	List() (decks []DeckSummary, err error)
+	Get(name string) (deck Deck, err error)
+	Save(deck Deck) (saved Deck, err error)
+	Update(name string, deck Deck) (updated Deck, err error)
+	Delete(name string) (err error)
+	SaveCard(deckName string, card DeckCard) (err error)
+	UpdateCard(deckName string, key string, card DeckCard) (err error)
+	DeleteCard(deckName string, key string) (err error)
+	Validate(deck Deck) (errs []error)
+	ValidateCard(card DeckCard) (errs []error)
+}
+
+type DeckModel struct {
+	DB *pgx.Conn
+}
+
+// Satisfied by both a connection and a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func (model *DeckModel) List() (decks []DeckSummary, err error) {
+	stmt := `
+		SELECT d.name, d.description, d.default_locale,
+			(SELECT count(*) FROM value_sort_deck_cards c WHERE c.deck_name = d.name),
+			ARRAY(SELECT DISTINCT t.locale FROM value_sort_deck_card_text t WHERE t.deck_name = d.name ORDER BY t.locale)
+		FROM value_sort_decks d
+		ORDER BY d.name;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	decks = []DeckSummary{}
+	for rows.Next() {
+		var deck DeckSummary
+		err = rows.Scan(&deck.Name, &deck.Description, &deck.DefaultLocale, &deck.CardCount, &deck.Locales)
+		if err != nil {
+			return nil, err
+		}
+		decks = append(decks, deck)
+	}
+	return decks, rows.Err()
+}
+
+func (model *DeckModel) Get(name string) (deck Deck, err error) {
+	return loadDeck(context.Background(), model.DB, name)
+}
+
+func loadDeck(ctx context.Context, q querier, name string) (deck Deck, err error) {
+	err = q.QueryRow(
+		ctx,
+		`SELECT name, description, default_locale FROM value_sort_decks WHERE name = $1`,
+		name,
+	).Scan(&deck.Name, &deck.Description, &deck.DefaultLocale)
+	if err != nil {
+		return Deck{}, err
+	}
+
+	stmt := `
+		SELECT c.card_key, t.locale, t.body, t.details
+		FROM value_sort_deck_cards c
+		JOIN value_sort_deck_card_text t ON t.deck_name = c.deck_name AND t.card_key = c.card_key
+		WHERE c.deck_name = $1
+		ORDER BY c.position, c.card_key;
+	`
+	rows, err := q.Query(ctx, stmt, name)
+	if err != nil {
+		return Deck{}, err
+	}
+	defer rows.Close()
+	deck.Cards = []DeckCard{}
+	for rows.Next() {
+		var key, locale string
+		var text CardText
+		err = rows.Scan(&key, &locale, &text.Body, &text.Details)
+		if err != nil {
+			return Deck{}, err
+		}
+		if len(deck.Cards) == 0 || deck.Cards[len(deck.Cards)-1].Key != key {
+			deck.Cards = append(deck.Cards, DeckCard{Key: key, Text: map[string]CardText{}})
+		}
+		deck.Cards[len(deck.Cards)-1].Text[locale] = text
+	}
+	return deck, rows.Err()
+}
+
+func (model *DeckModel) Save(deck Deck) (saved Deck, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return Deck{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO value_sort_decks (name, description, default_locale) VALUES ($1, $2, $3)`,
+		deck.Name, deck.Description, deck.DefaultLocale,
+	)
+	if err != nil {
+		return Deck{}, database.TranslateError(err)
+	}
+	for position, card := range deck.Cards {
+		err = insertDeckCard(ctx, tx, deck.Name, card, position)
+		if err != nil {
+			return Deck{}, err
+		}
+	}
+
+	saved, err = loadDeck(ctx, tx, deck.Name)
+	if err != nil {
+		return Deck{}, err
+	}
+	return saved, tx.Commit(ctx)
+}
+
+// Renames the deck or changes its description and default locale. Its cards
+// are edited on their own.
+func (model *DeckModel) Update(name string, deck Deck) (updated Deck, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return Deck{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(
+		ctx,
+		`UPDATE value_sort_decks SET name = $2, description = $3, default_locale = $4 WHERE name = $1`,
+		name, deck.Name, deck.Description, deck.DefaultLocale,
+	)
+	if err != nil {
+		return Deck{}, database.TranslateError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return Deck{}, pgx.ErrNoRows
+	}
+
+	var untranslated int
+	err = tx.QueryRow(
+		ctx,
+		`SELECT count(*) FROM value_sort_deck_cards c
+		WHERE c.deck_name = $1 AND NOT EXISTS (
+			SELECT 1 FROM value_sort_deck_card_text t
+			WHERE t.deck_name = c.deck_name AND t.card_key = c.card_key AND t.locale = $2
+		)`,
+		deck.Name, deck.DefaultLocale,
+	).Scan(&untranslated)
+	if err != nil {
+		return Deck{}, err
+	}
+	if untranslated > 0 {
+		return Deck{}, webserverutils.NewRequestError(fmt.Sprintf("%d cards have no '%s' text to fall back on", untranslated, deck.DefaultLocale))
+	}
+
+	updated, err = loadDeck(ctx, tx, deck.Name)
+	if err != nil {
+		return Deck{}, err
+	}
+	return updated, tx.Commit(ctx)
+}
+
+func (model *DeckModel) Delete(name string) (err error) {
+	result, err := model.DB.Exec(context.Background(), `DELETE FROM value_sort_decks WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Adds a card to the end of the deck
+func (model *DeckModel) SaveCard(deckName string, card DeckCard) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var defaultLocale string
+	var position int
+	err = tx.QueryRow(
+		ctx,
+		`SELECT d.default_locale, coalesce((SELECT max(position) + 1 FROM value_sort_deck_cards WHERE deck_name = d.name), 0)
+		FROM value_sort_decks d WHERE d.name = $1
+		FOR UPDATE`,
+		deckName,
+	).Scan(&defaultLocale, &position)
+	if err != nil {
+		return err
+	}
+	err = requireLocale(card, defaultLocale)
+	if err != nil {
+		return err
+	}
+	err = insertDeckCard(ctx, tx, deckName, card, position)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Replaces the card's text in every locale
+func (model *DeckModel) UpdateCard(deckName string, key string, card DeckCard) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var defaultLocale string
+	err = tx.QueryRow(
+		ctx,
+		`SELECT d.default_locale FROM value_sort_decks d
+		JOIN value_sort_deck_cards c ON c.deck_name = d.name
+		WHERE d.name = $1 AND c.card_key = $2`,
+		deckName, key,
+	).Scan(&defaultLocale)
+	if err != nil {
+		return err
+	}
+	err = requireLocale(card, defaultLocale)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM value_sort_deck_card_text WHERE deck_name = $1 AND card_key = $2`, deckName, key)
+	if err != nil {
+		return err
+	}
+	err = insertCardText(ctx, tx, deckName, key, card.Text)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (model *DeckModel) DeleteCard(deckName string, key string) (err error) {
+	result, err := model.DB.Exec(
+		context.Background(),
+		`DELETE FROM value_sort_deck_cards WHERE deck_name = $1 AND card_key = $2`,
+		deckName, key,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func insertDeckCard(ctx context.Context, tx pgx.Tx, deckName string, card DeckCard, position int) error {
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO value_sort_deck_cards (deck_name, card_key, position) VALUES ($1, $2, $3)`,
+		deckName, card.Key, position,
+	)
+	if err != nil {
+		return database.TranslateError(err)
+	}
+	return insertCardText(ctx, tx, deckName, card.Key, card.Text)
+}
+
+func insertCardText(ctx context.Context, tx pgx.Tx, deckName string, key string, text map[string]CardText) error {
+	for locale, t := range text {
+		_, err := tx.Exec(
+			ctx,
+			`INSERT INTO value_sort_deck_card_text (deck_name, card_key, locale, body, details) VALUES ($1, $2, $3, $4, $5)`,
+			deckName, key, locale, t.Body, t.Details,
+		)
+		if err != nil {
+			return database.TranslateError(err)
+		}
+	}
+	return nil
+}
+
+// Every card needs text in the deck's default locale to fall back on
+func requireLocale(card DeckCard, locale string) error {
+	if _, ok := card.Text[locale]; !ok {
+		return webserverutils.NewRequestError(fmt.Sprintf("card '%s' is missing text for the deck's default locale '%s'", card.Key, locale))
+	}
+	return nil
+}
+
+func (model *DeckModel) Validate(deck Deck) (errs []error) {
+	errs = []error{}
+	if !slugPattern.MatchString(deck.Name) {
+		errs = append(errs, errors.New("name must be lowercase letters, numbers and dashes"))
+	}
+	if !localePattern.MatchString(deck.DefaultLocale) {
+		errs = append(errs, fmt.Errorf("invalid defaultLocale '%s'", deck.DefaultLocale))
+	}
+	keys := map[string]bool{}
+	for _, card := range deck.Cards {
+		errs = append(errs, model.ValidateCard(card)...)
+		if keys[card.Key] {
+			errs = append(errs, fmt.Errorf("card '%s' appears more than once", card.Key))
+		}
+		keys[card.Key] = true
+		if _, ok := card.Text[deck.DefaultLocale]; !ok && deck.DefaultLocale != "" {
+			errs = append(errs, fmt.Errorf("card '%s' is missing text for the default locale '%s'", card.Key, deck.DefaultLocale))
+		}
+	}
+	return errs
+}
+
+func (model *DeckModel) ValidateCard(card DeckCard) (errs []error) {
+	errs = []error{}
+	if !slugPattern.MatchString(card.Key) {
+		errs = append(errs, fmt.Errorf("card key '%s' must be lowercase letters, numbers and dashes", card.Key))
+	}
+	if len(card.Text) == 0 {
+		errs = append(errs, fmt.Errorf("card '%s' has no text", card.Key))
+	}
+	locales := []string{}
+	for locale := range card.Text {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		if !localePattern.MatchString(locale) {
+			errs = append(errs, fmt.Errorf("card '%s' has an invalid locale '%s'", card.Key, locale))
+		}
+		if strings.TrimSpace(card.Text[locale].Body) == "" {
+			errs = append(errs, fmt.Errorf("card '%s' is missing a body for '%s'", card.Key, locale))
+		}
+	}
+	return errs
+}
+
+// The card's text in the locale asked for, falling back to its language
+// without the region (pt-BR to pt), then the deck's default
+func (card DeckCard) TextFor(locale string, defaultLocale string) CardText {
+	if text, ok := card.Text[locale]; ok {
+		return text
+	}
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		if text, ok := card.Text[locale[:idx]]; ok {
+			return text
+		}
+	}
+	return card.Text[defaultLocale]
+}
@@ -0,0 +1,184 @@
+package valuesort
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type MockDeckModel struct {
+	*DeckModel
+	decks map[string]Deck
+}
+
+func (model MockDeckModel) List() ([]DeckSummary, error) {
+	decks := []DeckSummary{}
+	for _, deck := range model.decks {
+		decks = append(decks, DeckSummary{Name: deck.Name, DefaultLocale: deck.DefaultLocale, CardCount: len(deck.Cards)})
+	}
+	return decks, nil
+}
+func (model MockDeckModel) Get(name string) (Deck, error) {
+	deck, ok := model.decks[name]
+	if !ok {
+		return Deck{}, errors.New("no rows in result set")
+	}
+	return deck, nil
+}
+func (model MockDeckModel) Save(deck Deck) (Deck, error) { return deck, nil }
+func (model MockDeckModel) Update(name string, deck Deck) (Deck, error) {
+	if _, ok := model.decks[name]; !ok {
+		return Deck{}, errors.New("no rows in result set")
+	}
+	return deck, nil
+}
+func (model MockDeckModel) Delete(name string) error {
+	_, err := model.Get(name)
+	return err
+}
+func (model MockDeckModel) SaveCard(deckName string, card DeckCard) error {
+	deck, err := model.Get(deckName)
+	if err != nil {
+		return err
+	}
+	return requireLocale(card, deck.DefaultLocale)
+}
+func (model MockDeckModel) UpdateCard(deckName string, key string, card DeckCard) error {
+	return model.SaveCard(deckName, card)
+}
+func (model MockDeckModel) DeleteCard(deckName string, key string) error {
+	_, err := model.Get(deckName)
+	return err
+}
+
+func mockDecks() MockDeckModel {
+	return MockDeckModel{
+		DeckModel: &DeckModel{},
+		decks: map[string]Deck{
+			DefaultDeck: {
+				Name:          DefaultDeck,
+				DefaultLocale: "en",
+				Cards: []DeckCard{
+					{Key: "acceptance", Text: map[string]CardText{"en": {Body: "ACCEPTANCE", Details: "to be accepted as I am"}}},
+				},
+			},
+		},
+	}
+}
+
+func serveDeck(t *testing.T, handler http.HandlerFunc, method string, vars map[string]string, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, "/api/v1/value-sort/decks", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, mux.SetURLVars(req, vars))
+	return rr
+}
+
+func TestGetDeckHandler(t *testing.T) {
+	model := mockDecks()
+	rr := serveDeck(t, GetDeckHandler(model), "GET", map[string]string{"deckName": DefaultDeck}, "")
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d", 200, rr.Code)
+	}
+	var deck Deck
+	err := json.Unmarshal(rr.Body.Bytes(), &deck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deck.Cards) != 1 || deck.Cards[0].Text["en"].Body != "ACCEPTANCE" {
+		t.Errorf("expected the default deck but received %+v", deck)
+	}
+
+	rr = serveDeck(t, GetDeckHandler(model), "GET", map[string]string{"deckName": "missing-deck"}, "")
+	if rr.Code != 404 {
+		t.Errorf("expected status code %d but received %d", 404, rr.Code)
+	}
+}
+
+func TestCreateDeckHandler(t *testing.T) {
+	model := mockDecks()
+	cases := []struct {
+		body         string
+		expectedCode int
+	}{
+		{`{"name": "work-values", "defaultLocale": "en", "cards": [{"key": "focus", "text": {"en": {"body": "FOCUS"}, "de": {"body": "FOKUS"}}}]}`, 201},
+		{`{"name": "Work Values", "defaultLocale": "en", "cards": []}`, 422},
+		{`{"name": "work-values", "defaultLocale": "english", "cards": []}`, 422},
+		{`{"name": "work-values", "defaultLocale": "en", "cards": [{"key": "focus", "text": {"de": {"body": "FOKUS"}}}]}`, 422},
+		{`{"name": "work-values", "defaultLocale": "en", "cards": [{"key": "focus", "text": {"en": {"body": ""}}}]}`, 422},
+		{`{"name": "work-values", "defaultLocale": "en", "cards": [{"key": "a", "text": {"en": {"body": "A"}}}, {"key": "a", "text": {"en": {"body": "B"}}}]}`, 422},
+	}
+	for _, c := range cases {
+		rr := serveDeck(t, CreateDeckHandler(model), "POST", nil, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d - %s", c.expectedCode, c.body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestDefaultDeckIsKept(t *testing.T) {
+	model := mockDecks()
+	vars := map[string]string{"deckName": DefaultDeck}
+	rr := serveDeck(t, DeleteDeckHandler(model), "DELETE", vars, "")
+	if rr.Code != 422 {
+		t.Errorf("expected status code %d deleting the default deck but received %d", 422, rr.Code)
+	}
+	rr = serveDeck(t, UpdateDeckHandler(model), "PUT", vars, `{"name": "renamed", "defaultLocale": "en"}`)
+	if rr.Code != 422 {
+		t.Errorf("expected status code %d renaming the default deck but received %d", 422, rr.Code)
+	}
+	rr = serveDeck(t, UpdateDeckHandler(model), "PUT", vars, `{"name": "default", "description": "Values", "defaultLocale": "en"}`)
+	if rr.Code != 200 {
+		t.Errorf("expected status code %d describing the default deck but received %d", 200, rr.Code)
+	}
+}
+
+func TestUpdateDeckCardHandler(t *testing.T) {
+	model := mockDecks()
+	cases := []struct {
+		vars         map[string]string
+		body         string
+		expectedCode int
+	}{
+		{map[string]string{"deckName": DefaultDeck, "cardKey": "acceptance"}, `{"text": {"en": {"body": "ACCEPTANCE"}, "es": {"body": "ACEPTACIÓN"}}}`, 200},
+		{map[string]string{"deckName": DefaultDeck, "cardKey": "acceptance"}, `{"key": "other", "text": {"en": {"body": "OTHER"}}}`, 422},
+		{map[string]string{"deckName": DefaultDeck, "cardKey": "acceptance"}, `{"text": {"es": {"body": "ACEPTACIÓN"}}}`, 422},
+		{map[string]string{"deckName": "missing-deck", "cardKey": "acceptance"}, `{"text": {"en": {"body": "ACCEPTANCE"}}}`, 404},
+	}
+	for _, c := range cases {
+		rr := serveDeck(t, UpdateDeckCardHandler(model), "PUT", c.vars, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d - %s", c.expectedCode, c.body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestCardTextFor(t *testing.T) {
+	card := DeckCard{
+		Key: "acceptance",
+		Text: map[string]CardText{
+			"en":    {Body: "ACCEPTANCE"},
+			"pt":    {Body: "ACEITAÇÃO"},
+			"pt-PT": {Body: "ACEITAÇÃO (PT)"},
+		},
+	}
+	cases := map[string]string{
+		"pt-PT": "ACEITAÇÃO (PT)",
+		"pt-BR": "ACEITAÇÃO",
+		"pt":    "ACEITAÇÃO",
+		"de":    "ACCEPTANCE",
+		"":      "ACCEPTANCE",
+	}
+	for locale, expected := range cases {
+		if body := card.TextFor(locale, "en").Body; body != expected {
+			t.Errorf("expected '%s' for '%s' but received '%s'", expected, locale, body)
+		}
+	}
+}
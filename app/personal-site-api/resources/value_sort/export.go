@@ -0,0 +1,145 @@
+package valuesort
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// The formats a board can be exported in, and the content type each is sent with
+var exportContentTypes = map[string]string{
+	"csv": "text/csv; charset=utf-8",
+	"md":  "text/markdown; charset=utf-8",
+	"pdf": "application/pdf",
+}
+
+// A board's columns from most important to least, which is the reverse of
+// the order they're shown in
+func rankedColumns(board ValueSortBoard) []ValueSortColumn {
+	columns := append([]ValueSortColumn{}, board.Columns...)
+	sort.SliceStable(columns, func(i, j int) bool { return columns[i].Order > columns[j].Order })
+	return columns
+}
+
+// Writes a row per card, ranked from the first card of the most important
+// column down
+func ExportCSV(board ValueSortBoard) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err := writer.Write([]string{"rank", "column", "card", "details"})
+	if err != nil {
+		return nil, err
+	}
+	rank := 0
+	for _, col := range rankedColumns(board) {
+		for _, card := range col.Cards {
+			rank++
+			err = writer.Write([]string{strconv.Itoa(rank), csvText(col.Title), csvText(card.Body), csvText(card.Details)})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// Spreadsheets run cells starting with these as formulas, so they're
+// prefixed with a quote to be shown as text instead
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func ExportMarkdown(board ValueSortBoard) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", markdownEscape(board.Name))
+	rank := 0
+	for _, col := range rankedColumns(board) {
+		fmt.Fprintf(&buf, "\n## %s\n\n", markdownEscape(col.Title))
+		if len(col.Cards) == 0 {
+			buf.WriteString("_No cards_\n")
+			continue
+		}
+		for _, card := range col.Cards {
+			rank++
+			fmt.Fprintf(&buf, "%d. **%s**", rank, markdownEscape(card.Body))
+			if card.Details != "" {
+				fmt.Fprintf(&buf, " - %s", markdownEscape(card.Details))
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`, "[", `\[`, "]", `\]`, "\n", " ",
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// Lays the board out the same way as the markdown, with the core fonts so
+// nothing needs to be loaded from disk
+func ExportPDF(board ValueSortBoard) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	// the core fonts only cover Latin-1
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(board.Name, true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.MultiCell(0, 10, tr(board.Name), "", "L", false)
+	rank := 0
+	for _, col := range rankedColumns(board) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.MultiCell(0, 8, tr(col.Title), "B", "L", false)
+		pdf.Ln(1)
+		if len(col.Cards) == 0 {
+			pdf.SetFont("Helvetica", "I", 10)
+			pdf.MultiCell(0, 6, "No cards", "", "L", false)
+			continue
+		}
+		for _, card := range col.Cards {
+			rank++
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.MultiCell(0, 6, tr(fmt.Sprintf("%d. %s", rank, card.Body)), "", "L", false)
+			if card.Details != "" {
+				pdf.SetFont("Helvetica", "", 10)
+				pdf.SetX(pdf.GetX() + 6)
+				pdf.MultiCell(0, 5, tr(card.Details), "", "L", false)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Renders the board in one of exportContentTypes' formats
+func ExportBoard(board ValueSortBoard, format string) ([]byte, error) {
+	switch format {
+	case "csv":
+		return ExportCSV(board)
+	case "md":
+		return ExportMarkdown(board), nil
+	case "pdf":
+		return ExportPDF(board)
+	}
+	return nil, fmt.Errorf("unknown export format '%s'", format)
+}
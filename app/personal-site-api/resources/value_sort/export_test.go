@@ -0,0 +1,116 @@
+package valuesort
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func exportBoard() ValueSortBoard {
+	return ValueSortBoard{
+		Name: "some-board",
+		Columns: []ValueSortColumn{
+			{Title: "Unsorted", Order: 0, Cards: []ValueSortCard{{Body: "ADVENTURE", Details: "to have new and exciting experiences"}}},
+			{Title: "Most Important", Order: 2, Cards: []ValueSortCard{{Body: "ACCEPTANCE", Details: "to be accepted as I am"}, {Body: "FAMILY", Details: ""}}},
+			{Title: "Important", Order: 1, Cards: []ValueSortCard{}},
+		},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	content, err := ExportCSV(exportBoard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "rank,column,card,details\n" +
+		"1,Most Important,ACCEPTANCE,to be accepted as I am\n" +
+		"2,Most Important,FAMILY,\n" +
+		"3,Unsorted,ADVENTURE,to have new and exciting experiences\n"
+	if string(content) != expected {
+		t.Errorf("expected\n%s\nbut received\n%s", expected, content)
+	}
+}
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	board := ValueSortBoard{
+		Name: "some-board",
+		Columns: []ValueSortColumn{
+			{Title: "=Top", Order: 0, Cards: []ValueSortCard{
+				{Body: "+SUM(A1:A2)", Details: `=HYPERLINK("https://example.com")`},
+				{Body: "@ME", Details: "-1"},
+				{Body: "FAMILY", Details: "a + b = c"},
+			}},
+		},
+	}
+	content, err := ExportCSV(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "rank,column,card,details\n" +
+		`1,'=Top,'+SUM(A1:A2),"'=HYPERLINK(""https://example.com"")"` + "\n" +
+		"2,'=Top,'@ME,'-1\n" +
+		"3,'=Top,FAMILY,a + b = c\n"
+	if string(content) != expected {
+		t.Errorf("expected\n%s\nbut received\n%s", expected, content)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	content := string(ExportMarkdown(exportBoard()))
+	expected := "# some-board\n\n" +
+		"## Most Important\n\n1. **ACCEPTANCE** - to be accepted as I am\n2. **FAMILY**\n\n" +
+		"## Important\n\n_No cards_\n\n" +
+		"## Unsorted\n\n3. **ADVENTURE** - to have new and exciting experiences\n"
+	if content != expected {
+		t.Errorf("expected\n%s\nbut received\n%s", expected, content)
+	}
+	if escaped := markdownEscape("*bold* [link]"); escaped != `\*bold\* \[link\]` {
+		t.Errorf("expected markdown to be escaped but received %s", escaped)
+	}
+}
+
+func TestExportBoardHandler(t *testing.T) {
+	model := mockBoards()
+	cases := []struct {
+		boardName    string
+		format       string
+		expectedCode int
+		contentType  string
+		prefix       string
+	}{
+		{"some-board", "csv", 200, "text/csv; charset=utf-8", "rank,column,card,details"},
+		{"some-board", "md", 200, "text/markdown; charset=utf-8", "# some-board"},
+		{"some-board", "pdf", 200, "application/pdf", "%PDF"},
+		{"some-board", "docx", 422, "", ""},
+		{"some-board", "", 422, "", ""},
+		{"missing-board", "csv", 404, "", ""},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", "/api/v1/value-sort/boards/"+c.boardName+"/export?format="+c.format, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		ExportBoardHandler(model).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": c.boardName}))
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s as %q but received %d", c.expectedCode, c.boardName, c.format, rr.Code)
+			continue
+		}
+		if c.expectedCode != 200 {
+			continue
+		}
+		if contentType := rr.Header().Get("Content-Type"); contentType != c.contentType {
+			t.Errorf("expected content type %s but received %s", c.contentType, contentType)
+		}
+		if disposition := rr.Header().Get("Content-Disposition"); disposition != "attachment; filename=some-board."+c.format {
+			t.Errorf("expected an attachment but received %s", disposition)
+		}
+		if !bytes.HasPrefix(rr.Body.Bytes(), []byte(c.prefix)) {
+			t.Errorf("expected %s export to start with %s but received %s", c.format, c.prefix, strings.SplitN(rr.Body.String(), "\n", 2)[0])
+		}
+	}
+}
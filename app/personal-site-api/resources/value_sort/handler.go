@@ -3,15 +3,20 @@ package valuesort
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
 )
 
 func CreateBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
 	type CreateBoardReqBody struct {
 		BoardName string `json:"boardName"`
+		Deck      string `json:"deck"`
+		Locale    string `json:"locale"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -21,9 +26,25 @@ func CreateBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
 		err := decoder.Decode(&reqBody)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Could not process request body - %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+		if !validationPassed(w, ValidateBoardName(reqBody.BoardName)) {
+			return
+		}
+		if reqBody.Deck == "" {
+			reqBody.Deck = DefaultDeck
+		}
+		if reqBody.Locale != "" && !localePattern.MatchString(reqBody.Locale) {
+			msg := webserverutils.NewRequestError(fmt.Sprintf("invalid locale '%s'", reqBody.Locale))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		owner := r.Header.Get(OwnerHeader)
+		if !validationPassed(w, ValidateOwner(owner)) {
+			return
 		}
 
-		err = model.Create(reqBody.BoardName)
+		err = model.Create(reqBody.BoardName, reqBody.Deck, reqBody.Locale, owner)
 		if err != nil {
 			if strings.Contains(err.Error(), "Invalid Request Body:") {
 				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
@@ -37,19 +58,54 @@ func CreateBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
 	}
 }
 
-func GetBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+// The boards belonging to whoever's asking
+func ListBoardsHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		boardName := vars["boardName"]
-		board, err := model.Get(boardName)
+		owner := r.Header.Get(OwnerHeader)
+		if owner == "" {
+			msg := webserverutils.NewRequestError(fmt.Sprintf("the %s header is required", OwnerHeader))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if !validationPassed(w, ValidateOwner(owner)) {
+			return
+		}
 
+		boards, err := model.List(owner)
 		if err != nil {
-			http.Error(w, "problem fetching value sort cards", http.StatusInternalServerError)
+			fmt.Println(err.Error())
+			http.Error(w, "problem fetching boards", http.StatusInternalServerError)
 			return
 		}
+		writeJSON(w, http.StatusOK, boards)
+	}
+}
+
+func GetBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		boardName := vars["boardName"]
 
-		if len(board.Columns) == 0 {
-			http.Error(w, "board not found", http.StatusNotFound)
+		var board ValueSortBoard
+		var err error
+		if at := r.URL.Query().Get("at"); at != "" {
+			var atTime time.Time
+			atTime, err = time.Parse(time.RFC3339, at)
+			if err != nil {
+				msg := webserverutils.NewRequestError(fmt.Sprintf("at must be an RFC 3339 timestamp, not '%s'", at))
+				http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			board, err = model.GetAt(boardName, atTime)
+		} else {
+			board, err = model.Get(boardName)
+		}
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "board not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "problem fetching value sort cards", http.StatusInternalServerError)
+			}
 			return
 		}
 
@@ -65,20 +121,629 @@ func GetBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
 
 func UpdateBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		boardName := vars["boardName"]
+
 		var board ValueSortBoard
 		decoder := json.NewDecoder(r.Body)
 		decoder.DisallowUnknownFields()
 		err := decoder.Decode(&board)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Could not process request body - %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+		if board.Name == "" {
+			board.Name = boardName
+		}
+		if board.Name != boardName {
+			msg := webserverutils.NewRequestError(fmt.Sprintf("board name '%s' does not match '%s'", board.Name, boardName))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if !validationPassed(w, model.Validate(board)) {
+			return
 		}
 
-		err = model.Upsert(board)
+		err = model.Upsert(board, r.Header.Get(OwnerHeader))
 		if err != nil {
-			http.Error(w, "unable to update board", http.StatusInternalServerError)
+			writeModelError(w, err, "unable to update board")
 			return
 		}
 
 		w.Write([]byte("success"))
 	}
 }
+
+func DeleteBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		err := model.Delete(vars["boardName"], r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to delete board")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type boardNameReqBody struct {
+	Name string `json:"name"`
+}
+
+// Moves the board to a new name, which it's then found at
+func RenameBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		boardName := vars["boardName"]
+		var reqBody boardNameReqBody
+		if !decodeBody(w, r, &reqBody) || !validationPassed(w, ValidateBoardName(reqBody.Name)) {
+			return
+		}
+
+		err := model.Rename(boardName, reqBody.Name, r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to rename board")
+			return
+		}
+		board, err := model.Get(reqBody.Name)
+		if err != nil {
+			writeModelError(w, err, "problem fetching value sort cards")
+			return
+		}
+		location := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/rename"), boardName) + board.Name
+		w.Header().Set("Location", location)
+		writeJSON(w, http.StatusOK, board)
+	}
+}
+
+// Copies the board to a new one, which belongs to whoever made the copy
+func CloneBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		boardName := vars["boardName"]
+		var reqBody boardNameReqBody
+		if !decodeBody(w, r, &reqBody) || !validationPassed(w, ValidateBoardName(reqBody.Name)) {
+			return
+		}
+		owner := r.Header.Get(OwnerHeader)
+		if !validationPassed(w, ValidateOwner(owner)) {
+			return
+		}
+
+		err := model.Clone(boardName, reqBody.Name, owner)
+		if err != nil {
+			writeModelError(w, err, "unable to copy board")
+			return
+		}
+		board, err := model.Get(reqBody.Name)
+		if err != nil {
+			writeModelError(w, err, "problem fetching value sort cards")
+			return
+		}
+		location := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/clone"), boardName) + board.Name
+		w.Header().Set("Location", location)
+		writeJSON(w, http.StatusCreated, board)
+	}
+}
+
+// Every card move on the board, oldest first
+func GetBoardHistoryHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		moves, err := model.History(vars["boardName"])
+		if err != nil {
+			writeModelError(w, err, "problem fetching board history")
+			return
+		}
+		writeJSON(w, http.StatusOK, moves)
+	}
+}
+
+// The board's rounds, and which it's up to
+func GetRoundsHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		progress, err := model.GetRounds(vars["boardName"])
+		if err != nil {
+			writeModelError(w, err, "problem fetching rounds")
+			return
+		}
+		writeJSON(w, http.StatusOK, progress)
+	}
+}
+
+// Puts the board in guided mode with the rounds sent, starting from the first
+func UpdateRoundsHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var rounds []Round
+		if !decodeBody(w, r, &rounds) || !validationPassed(w, model.ValidateRounds(rounds)) {
+			return
+		}
+
+		err := model.SetRounds(vars["boardName"], rounds, r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to update rounds")
+			return
+		}
+		progress, err := model.GetRounds(vars["boardName"])
+		if err != nil {
+			writeModelError(w, err, "problem fetching rounds")
+			return
+		}
+		writeJSON(w, http.StatusOK, progress)
+	}
+}
+
+// Moves on to the next round, refused until the current one is finished
+func AdvanceRoundHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		progress, err := model.AdvanceRound(vars["boardName"], r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to advance round")
+			return
+		}
+		writeJSON(w, http.StatusOK, progress)
+	}
+}
+
+// The board's outcome as a download: ?format=csv, md or pdf
+func ExportBoardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		format := r.URL.Query().Get("format")
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			msg := webserverutils.NewRequestError(fmt.Sprintf("format must be csv, md or pdf, not '%s'", format))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		board, err := model.Get(vars["boardName"])
+		if err != nil {
+			writeModelError(w, err, "problem fetching value sort cards")
+			return
+		}
+		content, err := ExportBoard(board, format)
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "unable to export board", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": board.Name + "." + format}))
+		w.Write(content)
+	}
+}
+
+func CreateSnapshotHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	type CreateSnapshotReqBody struct {
+		Name string `json:"name"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var reqBody CreateSnapshotReqBody
+		if !decodeBody(w, r, &reqBody) || !validationPassed(w, ValidateSnapshotName(reqBody.Name)) {
+			return
+		}
+
+		snapshot, err := model.CreateSnapshot(vars["boardName"], reqBody.Name, r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to save snapshot")
+			return
+		}
+		writeJSON(w, http.StatusCreated, snapshot)
+	}
+}
+
+func GetSnapshotsHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		snapshots, err := model.ListSnapshots(vars["boardName"])
+		if err != nil {
+			writeModelError(w, err, "problem fetching snapshots")
+			return
+		}
+		writeJSON(w, http.StatusOK, snapshots)
+	}
+}
+
+func GetSnapshotHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		snapshot, err := model.GetSnapshot(vars["boardName"], vars["snapshotName"])
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "snapshot not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "problem fetching snapshot", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, snapshot)
+	}
+}
+
+// Compares two boards, each either as it is now or as one of its snapshots:
+// ?from=my-board&fromSnapshot=january&to=my-board
+func CompareBoardsHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("from") == "" || query.Get("to") == "" {
+			msg := webserverutils.NewRequestError("from and to boards are required")
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		from, ok := boardState(w, model, query.Get("from"), query.Get("fromSnapshot"))
+		if !ok {
+			return
+		}
+		to, ok := boardState(w, model, query.Get("to"), query.Get("toSnapshot"))
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, CompareBoards(from, to))
+	}
+}
+
+func boardState(w http.ResponseWriter, model ValueSortBoardDataAccessLayer, boardName string, snapshotName string) (ValueSortBoard, bool) {
+	if snapshotName == "" {
+		board, err := model.Get(boardName)
+		if err != nil {
+			writeModelError(w, err, "problem fetching value sort cards")
+			return ValueSortBoard{}, false
+		}
+		return board, true
+	}
+	snapshot, err := model.GetSnapshot(boardName, snapshotName)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			http.Error(w, fmt.Sprintf("snapshot '%s' of board '%s' not found", snapshotName, boardName), http.StatusNotFound)
+		} else {
+			http.Error(w, "problem fetching snapshot", http.StatusInternalServerError)
+		}
+		return ValueSortBoard{}, false
+	}
+	return *snapshot.Board, true
+}
+
+// Moves a single card, for boards being sorted by more than one person at once
+func MoveCardHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var move MoveRequest
+		if !decodeBody(w, r, &move) {
+			return
+		}
+		if strings.TrimSpace(move.CardBody) == "" || strings.TrimSpace(move.To) == "" {
+			msg := webserverutils.NewRequestError("cardBody and to are required")
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		// versions start at 1, so a move without one would never be stale
+		if move.BaseVersion < 1 {
+			msg := webserverutils.NewRequestError("baseVersion is required")
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		event, err := model.Move(vars["boardName"], move, r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to move card")
+			return
+		}
+		writeJSON(w, http.StatusOK, event)
+	}
+}
+
+// How often an idle event stream is sent a comment, so proxies don't close it
+var keepAliveInterval = 25 * time.Second
+
+// Streams the board's changes as server-sent events, starting with the whole
+// board. Moves can be applied as they come; any other event means the board
+// should be fetched again.
+func BoardEventsHandler(model ValueSortBoardDataAccessLayer, hub *BoardHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		// subscribe first so nothing's missed between fetching and listening
+		events, unsubscribe := hub.Subscribe(vars["boardName"])
+		defer unsubscribe()
+		board, err := model.Get(vars["boardName"])
+		if err != nil {
+			writeModelError(w, err, "problem fetching value sort cards")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		writeEvent(w, EventBoard, board.Version, board)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				writeEvent(w, event.Type, event.Version, event)
+				// there's nothing more to hear under this name
+				if event.Type == EventRenamed || event.Type == EventDeleted {
+					flusher.Flush()
+					return
+				}
+			case <-keepAlive.C:
+				w.Write([]byte(": keep-alive\n\n"))
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, eventType string, version int64, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", eventType, version, data)
+}
+
+// Replaces the board's columns and responds with the board as it now stands
+func UpdateColumnsHandler(model ValueSortBoardDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		boardName := vars["boardName"]
+
+		var columns []ColumnDefinition
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&columns)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not process request body - %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+		if !validationPassed(w, model.ValidateColumns(columns)) {
+			return
+		}
+
+		err = model.SetColumns(boardName, columns, r.Header.Get(OwnerHeader))
+		if err != nil {
+			writeModelError(w, err, "unable to update columns")
+			return
+		}
+
+		board, err := model.Get(boardName)
+		if err != nil {
+			http.Error(w, "problem fetching value sort cards", http.StatusInternalServerError)
+			return
+		}
+		jbytes, err := json.Marshal(board)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+func validationPassed(w http.ResponseWriter, errs []error) bool {
+	if len(errs) == 0 {
+		return true
+	}
+	errMsgs := []string{}
+	for _, err := range errs {
+		errMsgs = append(errMsgs, err.Error())
+	}
+	msg := webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+	http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+	return false
+}
+
+func writeModelError(w http.ResponseWriter, err error, message string) {
+	if err.Error() == "no rows in result set" {
+		http.Error(w, "board not found", http.StatusNotFound)
+	} else if err == ErrStaleVersion {
+		http.Error(w, err.Error(), http.StatusConflict)
+	} else if err == ErrNotOwner {
+		http.Error(w, err.Error(), http.StatusForbidden)
+	} else if strings.Contains(err.Error(), "Invalid Request Body:") {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	} else {
+		fmt.Println(err.Error())
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+func GetDecksHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		decks, err := model.List()
+		if err != nil {
+			fmt.Println(err.Error())
+			http.Error(w, "problem fetching decks", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, decks)
+	}
+}
+
+func GetDeckHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		deck, err := model.Get(vars["deckName"])
+		if err != nil {
+			writeDeckError(w, err, "problem fetching deck")
+			return
+		}
+		writeJSON(w, http.StatusOK, deck)
+	}
+}
+
+func CreateDeckHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var deck Deck
+		if !decodeBody(w, r, &deck) || !validationPassed(w, model.Validate(deck)) {
+			return
+		}
+
+		saved, err := model.Save(deck)
+		if err != nil {
+			writeDeckError(w, err, "unable to create deck")
+			return
+		}
+		writeJSON(w, http.StatusCreated, saved)
+	}
+}
+
+// Changes the deck's name, description or default locale. Cards sent along
+// are ignored, as they're edited one at a time.
+func UpdateDeckHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		deckName := vars["deckName"]
+
+		var deck Deck
+		if !decodeBody(w, r, &deck) {
+			return
+		}
+		deck.Cards = nil
+		if !validationPassed(w, model.Validate(deck)) {
+			return
+		}
+		if deckName == DefaultDeck && deck.Name != DefaultDeck {
+			msg := webserverutils.NewRequestError("the default deck can't be renamed")
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		updated, err := model.Update(deckName, deck)
+		if err != nil {
+			writeDeckError(w, err, "unable to update deck")
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+	}
+}
+
+func DeleteDeckHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		deckName := vars["deckName"]
+		if deckName == DefaultDeck {
+			msg := webserverutils.NewRequestError("the default deck can't be deleted")
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		err := model.Delete(deckName)
+		if err != nil {
+			writeDeckError(w, err, "unable to delete deck")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func CreateDeckCardHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var card DeckCard
+		if !decodeBody(w, r, &card) || !validationPassed(w, model.ValidateCard(card)) {
+			return
+		}
+
+		err := model.SaveCard(vars["deckName"], card)
+		if err != nil {
+			writeDeckError(w, err, "unable to add card")
+			return
+		}
+		writeJSON(w, http.StatusCreated, card)
+	}
+}
+
+// Replaces the card's text, which is how translations are added
+func UpdateDeckCardHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var card DeckCard
+		if !decodeBody(w, r, &card) {
+			return
+		}
+		if card.Key == "" {
+			card.Key = vars["cardKey"]
+		}
+		if card.Key != vars["cardKey"] {
+			msg := webserverutils.NewRequestError(fmt.Sprintf("card key '%s' does not match '%s'", card.Key, vars["cardKey"]))
+			http.Error(w, msg.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if !validationPassed(w, model.ValidateCard(card)) {
+			return
+		}
+
+		err := model.UpdateCard(vars["deckName"], card.Key, card)
+		if err != nil {
+			writeDeckError(w, err, "unable to update card")
+			return
+		}
+		writeJSON(w, http.StatusOK, card)
+	}
+}
+
+func DeleteDeckCardHandler(model DeckDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		err := model.DeleteCard(vars["deckName"], vars["cardKey"])
+		if err != nil {
+			writeDeckError(w, err, "unable to delete card")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Could not process request body - %s", err.Error()), http.StatusUnprocessableEntity)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	jbytes, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "internal error building response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(jbytes)
+}
+
+func writeDeckError(w http.ResponseWriter, err error, message string) {
+	if err.Error() == "no rows in result set" {
+		http.Error(w, "deck not found", http.StatusNotFound)
+	} else if strings.Contains(err.Error(), "Invalid Request Body:") {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	} else {
+		fmt.Println(err.Error())
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
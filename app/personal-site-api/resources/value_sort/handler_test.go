@@ -0,0 +1,217 @@
+package valuesort
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+type MockBoardModel struct {
+	*ValueSortBoardModel
+	boards     map[string]ValueSortBoard
+	upsertErr  error
+	setColumns *[]ColumnDefinition
+	created    *[]string
+}
+
+func (model MockBoardModel) Create(boardName string, deckName string, locale string, owner string) error {
+	if deckName != DefaultDeck {
+		return webserverutils.NewRequestError(fmt.Sprintf("unknown deck '%s'", deckName))
+	}
+	*model.created = []string{boardName, deckName, locale, owner}
+	return nil
+}
+func (model MockBoardModel) Get(boardName string) (ValueSortBoard, error) {
+	board, ok := model.boards[boardName]
+	if !ok {
+		return ValueSortBoard{}, errors.New("no rows in result set")
+	}
+	return board, nil
+}
+func (model MockBoardModel) Upsert(board ValueSortBoard, owner string) error {
+	if _, ok := model.boards[board.Name]; !ok {
+		return errors.New("no rows in result set")
+	}
+	return model.upsertErr
+}
+func (model MockBoardModel) SetColumns(boardName string, columns []ColumnDefinition, owner string) error {
+	if _, ok := model.boards[boardName]; !ok {
+		return errors.New("no rows in result set")
+	}
+	*model.setColumns = columns
+	return nil
+}
+
+func mockBoards() MockBoardModel {
+	return MockBoardModel{
+		ValueSortBoardModel: &ValueSortBoardModel{},
+		boards: map[string]ValueSortBoard{
+			"some-board": {
+				Name: "some-board",
+				Columns: []ValueSortColumn{
+					{Title: "Unsorted", Order: 0, Cards: []ValueSortCard{{Body: "ACCEPTANCE", Details: "to be accepted as I am"}}},
+					{Title: "Important", Order: 1, Cards: []ValueSortCard{}},
+				},
+			},
+		},
+		setColumns: &[]ColumnDefinition{},
+		created:    &[]string{},
+	}
+}
+
+func put(t *testing.T, handler http.HandlerFunc, boardName string, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("PUT", "/api/v1/value-sort/boards/"+boardName, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": boardName}))
+	return rr
+}
+
+func TestUpdateBoardHandler(t *testing.T) {
+	model := mockBoards()
+	cases := []struct {
+		name         string
+		boardName    string
+		body         string
+		upsertErr    error
+		expectedCode int
+	}{
+		{"success", "some-board", `{"name": "some-board", "columns": [{"title": "Important", "cards": [{"body": "ACCEPTANCE"}]}]}`, nil, 200},
+		{"name from path", "some-board", `{"columns": [{"title": "Unsorted", "cards": []}]}`, nil, 200},
+		{"duplicate column", "some-board", `{"columns": [{"title": "Unsorted", "cards": []}, {"title": "Unsorted", "cards": []}]}`, nil, 422},
+		{"mismatched name", "some-board", `{"name": "other-board", "columns": []}`, nil, 422},
+		{"duplicate card", "some-board", `{"name": "some-board", "columns": [{"title": "Unsorted", "cards": [{"body": "A"}, {"body": "A"}]}]}`, nil, 422},
+		{"unknown column", "some-board", `{"name": "some-board", "columns": []}`, webserverutils.NewRequestError("unknown column 'Nope'"), 422},
+		{"stale version", "some-board", `{"name": "some-board", "version": 2, "columns": []}`, ErrStaleVersion, 409},
+		{"missing board", "missing-board", `{"name": "missing-board", "columns": []}`, nil, 404},
+		{"db error", "some-board", `{"name": "some-board", "columns": []}`, errors.New("connection reset"), 500},
+	}
+	for _, c := range cases {
+		model.upsertErr = c.upsertErr
+		rr := put(t, UpdateBoardHandler(model), c.boardName, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("%s: expected status code %d but received %d - %s", c.name, c.expectedCode, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestGetBoardHandlerKeepsColumnOrder(t *testing.T) {
+	model := mockBoards()
+	req, err := http.NewRequest("GET", "/api/v1/value-sort/boards/some-board", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	GetBoardHandler(model).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": "some-board"}))
+
+	var board ValueSortBoard
+	err = json.Unmarshal(rr.Body.Bytes(), &board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(board.Columns) != 2 || board.Columns[0].Title != "Unsorted" || board.Columns[1].Title != "Important" {
+		t.Errorf("expected the columns in order but received %+v", board.Columns)
+	}
+}
+
+func TestUpdateColumnsHandler(t *testing.T) {
+	model := mockBoards()
+	body := `[{"title": "Unsorted", "order": 0}, {"title": "Top Five", "order": 1, "limit": 5, "previousTitle": "Important"}]`
+	rr := put(t, UpdateColumnsHandler(model), "some-board", body)
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d - %s", 200, rr.Code, rr.Body.String())
+	}
+	columns := *model.setColumns
+	if len(columns) != 2 || columns[1].PreviousTitle != "Important" || columns[1].Limit == nil || *columns[1].Limit != 5 {
+		t.Errorf("expected the columns to be passed through but received %+v", columns)
+	}
+
+	cases := []struct {
+		boardName    string
+		body         string
+		expectedCode int
+	}{
+		{"some-board", `[]`, 422},
+		{"some-board", `[{"title": "A", "order": 0}, {"title": "A", "order": 1}]`, 422},
+		{"some-board", `[{"title": "A", "order": 0}, {"title": "B", "order": 0}]`, 422},
+		{"some-board", `[{"title": "A", "order": 0, "limit": 0}]`, 422},
+		{"some-board", `[{"title": "A", "order": 0, "colour": "red"}]`, 422},
+		{"missing-board", `[{"title": "A", "order": 0}]`, 404},
+	}
+	for _, c := range cases {
+		rr := put(t, UpdateColumnsHandler(model), c.boardName, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d", c.expectedCode, c.body, rr.Code)
+		}
+	}
+}
+
+func TestLimitErrors(t *testing.T) {
+	two := 2
+	limits := map[string]*int{"Unsorted": nil, "Top Two": &two}
+	if err := limitErrors(map[string]int{"Unsorted": 40, "Top Two": 2}, limits); err != nil {
+		t.Errorf("expected no error but received %s", err)
+	}
+	err := limitErrors(map[string]int{"Top Two": 3}, limits)
+	if err == nil || !strings.Contains(err.Error(), "Invalid Request Body: column 'Top Two' holds at most 2 cards") {
+		t.Errorf("expected a limit error but received %v", err)
+	}
+}
+
+func TestCreateBoardHandler(t *testing.T) {
+	model := mockBoards()
+	cases := []struct {
+		body         string
+		owner        string
+		expectedCode int
+		expected     []string
+	}{
+		{`{"boardName": "new-board"}`, "", 200, []string{"new-board", DefaultDeck, "", ""}},
+		{`{"boardName": "new-board", "deck": "default", "locale": "pt-BR"}`, "owner-key", 200, []string{"new-board", DefaultDeck, "pt-BR", "owner-key"}},
+		{`{"boardName": "new-board", "deck": "missing-deck"}`, "", 422, nil},
+		{`{"boardName": "new-board", "locale": "not a locale"}`, "", 422, nil},
+		{`{"boardName": "new-board", "cards": []}`, "", 422, nil},
+		{`{"boardName": "new-board"}`, strings.Repeat("k", 101), 422, nil},
+		{`{"boardName": ""}`, "", 422, nil},
+		{`{"boardName": "  "}`, "", 422, nil},
+		{`{"boardName": "a/b"}`, "", 422, nil},
+	}
+	for _, c := range cases {
+		*model.created = nil
+		req, err := http.NewRequest("POST", "/api/v1/value-sort/boards", bytes.NewBufferString(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(OwnerHeader, c.owner)
+		rr := httptest.NewRecorder()
+		CreateBoardHandler(model).ServeHTTP(rr, req)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d", c.expectedCode, c.body, rr.Code)
+		}
+		if c.expected != nil && strings.Join(*model.created, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("expected the board to be created with %v but received %v", c.expected, *model.created)
+		}
+	}
+}
+
+func TestGetBoardHandlerMissingBoard(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/v1/value-sort/boards/missing-board", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	GetBoardHandler(mockBoards()).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": "missing-board"}))
+	if rr.Code != 404 {
+		t.Errorf("expected status code %d but received %d", 404, rr.Code)
+	}
+}
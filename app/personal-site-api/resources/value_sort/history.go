@@ -0,0 +1,145 @@
+package valuesort
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// A card arriving on the board (no From), leaving it (no To), or going from
+// one column to another
+type CardMove struct {
+	CardBody string    `json:"cardBody"`
+	From     *string   `json:"from"`
+	To       *string   `json:"to"`
+	MovedAt  time.Time `json:"movedAt"`
+}
+
+// Every move on the board, oldest first
+func (model *ValueSortBoardModel) History(boardName string) (moves []CardMove, err error) {
+	ctx := context.Background()
+	var name string
+	err = model.DB.QueryRow(ctx, `SELECT name FROM value_sort_boards WHERE name = $1`, boardName).Scan(&name)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `
+		SELECT card_body, from_column, to_column, moved_at
+		FROM value_sort_moves
+		WHERE board_name = $1
+		ORDER BY moved_at, id;
+	`
+	rows, err := model.DB.Query(ctx, stmt, boardName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	moves = []CardMove{}
+	for rows.Next() {
+		var move CardMove
+		err = rows.Scan(&move.CardBody, &move.From, &move.To, &move.MovedAt)
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, move)
+	}
+	return moves, rows.Err()
+}
+
+// The board as it stood at a point in time, replayed from its moves (or not
+// found, if it hadn't been created yet). Only changes of column are logged, so
+// this shows which column each card was in but not its place within it. The
+// board's current columns are used, with any a card was in then that have
+// since been removed added on the end.
+func (model *ValueSortBoardModel) GetAt(boardName string, at time.Time) (board ValueSortBoard, err error) {
+	current, err := model.Get(boardName)
+	if err != nil {
+		return ValueSortBoard{}, err
+	}
+	var created time.Time
+	err = model.DB.QueryRow(context.Background(), `SELECT date_created FROM value_sort_boards WHERE name = $1`, boardName).Scan(&created)
+	if err != nil {
+		return ValueSortBoard{}, err
+	}
+	if at.Before(created) {
+		return ValueSortBoard{}, pgx.ErrNoRows
+	}
+
+	stmt := `
+		SELECT DISTINCT ON (card_body) card_body, card_details, to_column
+		FROM value_sort_moves
+		WHERE board_name = $1 AND moved_at <= $2
+		ORDER BY card_body, moved_at DESC, id DESC;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, boardName, at)
+	if err != nil {
+		return ValueSortBoard{}, err
+	}
+	defer rows.Close()
+	placed := []placedCard{}
+	for rows.Next() {
+		var card placedCard
+		var details *string
+		err = rows.Scan(&card.Body, &details, &card.column)
+		if err != nil {
+			return ValueSortBoard{}, err
+		}
+		if card.column == nil {
+			continue
+		}
+		if details != nil {
+			card.Details = *details
+		}
+		placed = append(placed, card)
+	}
+	if rows.Err() != nil {
+		return ValueSortBoard{}, rows.Err()
+	}
+	return replay(current, placed), nil
+}
+
+type placedCard struct {
+	ValueSortCard
+	column *string
+}
+
+// Lays the cards out in the board's columns. Where a card sat within its
+// column isn't known, so each column's cards are listed by body.
+func replay(current ValueSortBoard, placed []placedCard) ValueSortBoard {
+	board := ValueSortBoard{Name: current.Name, Columns: []ValueSortColumn{}}
+	columnIdx := map[string]int{}
+	for _, col := range current.Columns {
+		columnIdx[col.Title] = len(board.Columns)
+		board.Columns = append(board.Columns, ValueSortColumn{Title: col.Title, Order: col.Order, Limit: col.Limit, Cards: []ValueSortCard{}})
+	}
+
+	sort.Slice(placed, func(i, j int) bool {
+		return placed[i].Body < placed[j].Body
+	})
+	removed := []string{}
+	for _, card := range placed {
+		title := *card.column
+		if _, ok := columnIdx[title]; !ok {
+			removed = append(removed, title)
+			columnIdx[title] = -1
+		}
+	}
+	sort.Strings(removed)
+	for _, title := range removed {
+		order := 0
+		if len(board.Columns) > 0 {
+			order = board.Columns[len(board.Columns)-1].Order + 1
+		}
+		columnIdx[title] = len(board.Columns)
+		board.Columns = append(board.Columns, ValueSortColumn{Title: title, Order: order, Cards: []ValueSortCard{}})
+	}
+
+	for _, card := range placed {
+		idx := columnIdx[*card.column]
+		board.Columns[idx].Cards = append(board.Columns[idx].Cards, card.ValueSortCard)
+	}
+	return board
+}
@@ -0,0 +1,113 @@
+package valuesort
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type MockHistoryModel struct {
+	MockBoardModel
+	moves []CardMove
+	at    *time.Time
+}
+
+func (model MockHistoryModel) History(boardName string) ([]CardMove, error) {
+	if _, ok := model.boards[boardName]; !ok {
+		return nil, errors.New("no rows in result set")
+	}
+	return model.moves, nil
+}
+func (model MockHistoryModel) GetAt(boardName string, at time.Time) (ValueSortBoard, error) {
+	*model.at = at
+	return model.Get(boardName)
+}
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+
+func TestGetBoardHistoryHandler(t *testing.T) {
+	movedAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	model := MockHistoryModel{
+		MockBoardModel: mockBoards(),
+		moves: []CardMove{
+			{CardBody: "ACCEPTANCE", To: strPtr("Unsorted"), MovedAt: movedAt},
+			{CardBody: "ACCEPTANCE", From: strPtr("Unsorted"), To: strPtr("Important"), MovedAt: movedAt.Add(time.Minute)},
+		},
+	}
+	req, err := http.NewRequest("GET", "/api/v1/value-sort/boards/some-board/history", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	GetBoardHistoryHandler(model).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": "some-board"}))
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d", 200, rr.Code)
+	}
+	expected := `[{"cardBody":"ACCEPTANCE","from":null,"to":"Unsorted","movedAt":"2022-01-02T03:04:05Z"},{"cardBody":"ACCEPTANCE","from":"Unsorted","to":"Important","movedAt":"2022-01-02T03:05:05Z"}]`
+	if rr.Body.String() != expected {
+		t.Errorf("expected %s but received %s", expected, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	GetBoardHistoryHandler(model).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": "missing-board"}))
+	if rr.Code != 404 {
+		t.Errorf("expected status code %d but received %d", 404, rr.Code)
+	}
+}
+
+func TestGetBoardHandlerAt(t *testing.T) {
+	model := MockHistoryModel{MockBoardModel: mockBoards(), at: &time.Time{}}
+	cases := []struct {
+		query        string
+		expectedCode int
+	}{
+		{"?at=2022-01-02T03:04:05Z", 200},
+		{"?at=2022-01-02", 422},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", "/api/v1/value-sort/boards/some-board"+c.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		GetBoardHandler(model).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": "some-board"}))
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d", c.expectedCode, c.query, rr.Code)
+		}
+	}
+	if !model.at.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("expected the board to be replayed at the time asked for but received %s", model.at)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	current := ValueSortBoard{
+		Name: "some-board",
+		Columns: []ValueSortColumn{
+			{Title: "Unsorted", Order: 0},
+			{Title: "Important", Order: 1},
+		},
+	}
+	placed := []placedCard{
+		{ValueSortCard{Body: "CARING"}, strPtr("Important")},
+		{ValueSortCard{Body: "WEALTH"}, strPtr("Somewhat Important")},
+		{ValueSortCard{Body: "BEAUTY"}, strPtr("Important")},
+		{ValueSortCard{Body: "ART"}, strPtr("Unsorted")},
+	}
+	board := replay(current, placed)
+
+	got, _ := json.Marshal(board)
+	expected := `{"name":"some-board","columns":[` +
+		`{"title":"Unsorted","cards":[{"body":"ART","details":""}],"order":0},` +
+		`{"title":"Important","cards":[{"body":"BEAUTY","details":""},{"body":"CARING","details":""}],"order":1},` +
+		`{"title":"Somewhat Important","cards":[{"body":"WEALTH","details":""}],"order":2}]}`
+	if string(got) != expected {
+		t.Errorf("expected %s but received %s", expected, got)
+	}
+}
@@ -0,0 +1,308 @@
+package valuesort
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+// Every instance of the service listens here, so clients hear about changes
+// whichever instance made them
+const boardEventsChannel = "value_sort_board_events"
+
+const (
+	// A card moved, which clients can apply themselves
+	EventMove = "move"
+	// Anything else changed, and clients should fetch the board again
+	EventBoard = "board"
+	// The board now goes by RenamedTo, and clients should follow it there
+	EventRenamed = "renamed"
+	// The board is gone
+	EventDeleted = "deleted"
+)
+
+var ErrStaleVersion = errors.New("the board has changed since that version")
+
+type BoardEvent struct {
+	Type      string         `json:"type"`
+	Board     string         `json:"board"`
+	Version   int64          `json:"version"`
+	Move      *CardPlacement `json:"move,omitempty"`
+	RenamedTo string         `json:"renamedTo,omitempty"`
+}
+
+type CardPlacement struct {
+	CardBody string `json:"cardBody"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to"`
+	Position int    `json:"position"`
+}
+
+// A move made from the board as the client saw it at BaseVersion. It's only
+// refused if that card has been moved since, so people moving different cards
+// at the same time don't get in each other's way.
+type MoveRequest struct {
+	CardBody    string `json:"cardBody"`
+	To          string `json:"to"`
+	Position    int    `json:"position"`
+	BaseVersion int64  `json:"baseVersion"`
+}
+
+// Sent when the transaction commits, and not at all if it doesn't
+func notify(ctx context.Context, tx pgx.Tx, event BoardEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `SELECT pg_notify($1, $2)`, boardEventsChannel, string(payload))
+	return err
+}
+
+// Moves one card, shuffling the others in its old and new columns along
+func (model *ValueSortBoardModel) Move(boardName string, move MoveRequest, owner string) (event BoardEvent, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	version, err := bumpVersion(ctx, tx, boardName)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	var from string
+	var fromPosition int
+	var cardVersion int64
+	err = tx.QueryRow(
+		ctx,
+		`SELECT column_name, position, version FROM value_sort_cards WHERE board_name = $1 AND card_body = $2 FOR UPDATE`,
+		boardName, move.CardBody,
+	).Scan(&from, &fromPosition, &cardVersion)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return BoardEvent{}, webserverutils.NewRequestError(fmt.Sprintf("unknown card '%s'", move.CardBody))
+		}
+		return BoardEvent{}, err
+	}
+	if cardVersion > move.BaseVersion {
+		return BoardEvent{}, ErrStaleVersion
+	}
+
+	limits, err := columnLimits(ctx, tx, boardName)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	limits, err = roundLimits(ctx, tx, boardName, limits)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	limit, ok := limits[move.To]
+	if !ok {
+		return BoardEvent{}, webserverutils.NewRequestError(fmt.Sprintf("unknown column '%s'", move.To))
+	}
+	var others int
+	err = tx.QueryRow(
+		ctx,
+		`SELECT count(*) FROM value_sort_cards WHERE board_name = $1 AND column_name = $2 AND card_body <> $3`,
+		boardName, move.To, move.CardBody,
+	).Scan(&others)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	if limit != nil && others >= *limit {
+		return BoardEvent{}, webserverutils.NewRequestError(fmt.Sprintf("column '%s' holds at most %d cards", move.To, *limit))
+	}
+	position := move.Position
+	if position < 0 || position > others {
+		position = others
+	}
+
+	// close the gap it leaves, then make room where it's going
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE value_sort_cards SET position = position - 1
+		WHERE board_name = $1 AND column_name = $2 AND position > $3 AND card_body <> $4`,
+		boardName, from, fromPosition, move.CardBody,
+	)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE value_sort_cards SET position = position + 1
+		WHERE board_name = $1 AND column_name = $2 AND position >= $3 AND card_body <> $4`,
+		boardName, move.To, position, move.CardBody,
+	)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE value_sort_cards SET column_name = $3, position = $4, version = $5
+		WHERE board_name = $1 AND card_body = $2`,
+		boardName, move.CardBody, move.To, position, version,
+	)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	if from != move.To {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO value_sort_moves (board_name, card_body, card_details, from_column, to_column, position)
+			SELECT board_name, card_body, card_details, $3, column_name, position
+			FROM value_sort_cards WHERE board_name = $1 AND card_body = $2`,
+			boardName, move.CardBody, from,
+		)
+		if err != nil {
+			return BoardEvent{}, err
+		}
+	}
+
+	event = BoardEvent{
+		Type:    EventMove,
+		Board:   boardName,
+		Version: version,
+		Move:    &CardPlacement{CardBody: move.CardBody, From: from, To: move.To, Position: position},
+	}
+	err = notify(ctx, tx, event)
+	if err != nil {
+		return BoardEvent{}, err
+	}
+	return event, tx.Commit(ctx)
+}
+
+// Passes board events from Postgres on to the clients watching each board.
+// Listening needs a connection of its own.
+type BoardHub struct {
+	Connect func() (*pgx.Conn, error)
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan BoardEvent]bool
+}
+
+func NewBoardHub(connect func() (*pgx.Conn, error)) *BoardHub {
+	return &BoardHub{
+		Connect:     connect,
+		subscribers: map[string]map[chan BoardEvent]bool{},
+	}
+}
+
+// Listens in the background, reconnecting if the connection drops
+func (hub *BoardHub) Start() {
+	go func() {
+		backoff := time.Second
+		connected := false
+		for {
+			err := hub.listen(func() {
+				backoff = time.Second
+				// anything sent while we were away was missed
+				if connected {
+					hub.resync()
+				}
+				connected = true
+			})
+			fmt.Printf("value sort: board events listener stopped: %s\n", err.Error())
+			time.Sleep(backoff)
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+	}()
+}
+
+func (hub *BoardHub) listen(onListening func()) error {
+	conn, err := hub.Connect()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	defer conn.Close(ctx)
+	_, err = conn.Exec(ctx, "LISTEN "+boardEventsChannel)
+	if err != nil {
+		return err
+	}
+	onListening()
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		hub.dispatch(notification.Payload)
+	}
+}
+
+// The returned channel is closed if the subscriber falls too far behind, in
+// which case it should reconnect and start again from the current board
+func (hub *BoardHub) Subscribe(boardName string) (events <-chan BoardEvent, unsubscribe func()) {
+	ch := make(chan BoardEvent, 32)
+	hub.mu.Lock()
+	if hub.subscribers[boardName] == nil {
+		hub.subscribers[boardName] = map[chan BoardEvent]bool{}
+	}
+	hub.subscribers[boardName][ch] = true
+	hub.mu.Unlock()
+
+	return ch, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		hub.remove(boardName, ch)
+	}
+}
+
+func (hub *BoardHub) dispatch(payload string) {
+	var event BoardEvent
+	err := json.Unmarshal([]byte(payload), &event)
+	if err != nil {
+		fmt.Printf("value sort: ignoring malformed board event: %s\n", err.Error())
+		return
+	}
+	hub.publish(event)
+}
+
+func (hub *BoardHub) publish(event BoardEvent) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for ch := range hub.subscribers[event.Board] {
+		select {
+		case ch <- event:
+		default:
+			hub.remove(event.Board, ch)
+		}
+	}
+}
+
+// Has everyone fetch their board again
+func (hub *BoardHub) resync() {
+	hub.mu.Lock()
+	boards := []string{}
+	for boardName := range hub.subscribers {
+		boards = append(boards, boardName)
+	}
+	hub.mu.Unlock()
+	for _, boardName := range boards {
+		hub.publish(BoardEvent{Type: EventBoard, Board: boardName})
+	}
+}
+
+// Must be called with the lock held
+func (hub *BoardHub) remove(boardName string, ch chan BoardEvent) {
+	if !hub.subscribers[boardName][ch] {
+		return
+	}
+	delete(hub.subscribers[boardName], ch)
+	close(ch)
+	if len(hub.subscribers[boardName]) == 0 {
+		delete(hub.subscribers, boardName)
+	}
+}
@@ -0,0 +1,194 @@
+package valuesort
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+type MockMoveModel struct {
+	MockBoardModel
+}
+
+func (model MockMoveModel) Move(boardName string, move MoveRequest, owner string) (BoardEvent, error) {
+	if _, ok := model.boards[boardName]; !ok {
+		return BoardEvent{}, errors.New("no rows in result set")
+	}
+	switch {
+	case move.BaseVersion < 3:
+		return BoardEvent{}, ErrStaleVersion
+	case move.To == "Nowhere":
+		return BoardEvent{}, webserverutils.NewRequestError("unknown column 'Nowhere'")
+	}
+	return BoardEvent{Type: EventMove, Board: boardName, Version: 4, Move: &CardPlacement{CardBody: move.CardBody, To: move.To, Position: move.Position}}, nil
+}
+
+func TestMoveCardHandler(t *testing.T) {
+	model := MockMoveModel{mockBoards()}
+	cases := []struct {
+		boardName    string
+		body         string
+		expectedCode int
+	}{
+		{"some-board", `{"cardBody": "ACCEPTANCE", "to": "Important", "position": 0, "baseVersion": 3}`, 200},
+		{"some-board", `{"cardBody": "ACCEPTANCE", "to": "Important", "position": 0, "baseVersion": 2}`, 409},
+		{"some-board", `{"cardBody": "ACCEPTANCE", "to": "Nowhere", "position": 0, "baseVersion": 3}`, 422},
+		{"some-board", `{"to": "Important", "baseVersion": 3}`, 422},
+		{"some-board", `{"cardBody": "ACCEPTANCE", "to": "Important", "position": 0}`, 422},
+		{"some-board", `{"cardBody": "ACCEPTANCE", "to": "Important", "position": 0, "baseVersion": 0}`, 422},
+		{"missing-board", `{"cardBody": "ACCEPTANCE", "to": "Important", "baseVersion": 3}`, 404},
+	}
+	for _, c := range cases {
+		rr := put(t, MoveCardHandler(model), c.boardName, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d", c.expectedCode, c.body, rr.Code)
+		}
+	}
+}
+
+func TestBoardHub(t *testing.T) {
+	hub := NewBoardHub(nil)
+	events, unsubscribe := hub.Subscribe("some-board")
+	other, unsubscribeOther := hub.Subscribe("other-board")
+	defer unsubscribeOther()
+
+	hub.dispatch(`{"type": "move", "board": "some-board", "version": 2, "move": {"cardBody": "ART", "to": "Important", "position": 1}}`)
+	hub.dispatch(`not json`)
+	select {
+	case event := <-events:
+		if event.Version != 2 || event.Move == nil || event.Move.CardBody != "ART" {
+			t.Errorf("expected the move event but received %+v", event)
+		}
+	default:
+		t.Fatal("expected an event for the board")
+	}
+	select {
+	case event := <-other:
+		t.Errorf("expected no events for another board but received %+v", event)
+	default:
+	}
+
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed once unsubscribed")
+	}
+	unsubscribe()
+
+	// a subscriber that stops reading is dropped rather than holding up the rest
+	for i := 0; i < 100; i++ {
+		hub.publish(BoardEvent{Type: EventBoard, Board: "other-board", Version: int64(i)})
+	}
+	received := 0
+	for range other {
+		received++
+	}
+	if received != cap(other) {
+		t.Errorf("expected %d events before being dropped but received %d", cap(other), received)
+	}
+}
+
+func TestBoardEventsHandler(t *testing.T) {
+	defer func(d time.Duration) { keepAliveInterval = d }(keepAliveInterval)
+	keepAliveInterval = 20 * time.Millisecond
+
+	model := mockBoards()
+	board := model.boards["some-board"]
+	board.Version = 3
+	model.boards["some-board"] = board
+	hub := NewBoardHub(nil)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/boards/{boardName}/events", BoardEventsHandler(model, hub))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/boards/missing-board/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 404 {
+		t.Errorf("expected status code %d but received %d", 404, resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/boards/some-board/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected an event stream but received '%s'", contentType)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the event stream")
+			return ""
+		}
+	}
+
+	if line := next(); line != "event: board" {
+		t.Fatalf("expected the board first but received '%s'", line)
+	}
+	if line := next(); line != "id: 3" {
+		t.Errorf("expected the board's version as the id but received '%s'", line)
+	}
+	if line := next(); !strings.HasPrefix(line, `data: {"name":"some-board","version":3,`) {
+		t.Errorf("expected the board but received '%s'", line)
+	}
+	next()
+
+	hub.dispatch(`{"type": "move", "board": "some-board", "version": 4, "move": {"cardBody": "ACCEPTANCE", "from": "Unsorted", "to": "Important", "position": 0}}`)
+	expected := []string{
+		"event: move",
+		"id: 4",
+		`data: {"type":"move","board":"some-board","version":4,"move":{"cardBody":"ACCEPTANCE","from":"Unsorted","to":"Important","position":0}}`,
+	}
+	for _, want := range expected {
+		line := next()
+		for line == ": keep-alive" || line == "" {
+			line = next()
+		}
+		if line != want {
+			t.Errorf("expected '%s' but received '%s'", want, line)
+		}
+	}
+
+	// a renamed board's stream ends once clients have been told where it went
+	hub.dispatch(`{"type": "renamed", "board": "some-board", "version": 5, "renamedTo": "new-board"}`)
+	renamed := false
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if !renamed {
+					t.Error("expected the renamed event before the stream ended")
+				}
+				return
+			}
+			if strings.Contains(line, `"renamedTo":"new-board"`) {
+				renamed = true
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected the stream to end after the board was renamed")
+		}
+	}
+}
@@ -2,11 +2,15 @@ package valuesort
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
 )
 
 // A struct to model the object
@@ -19,18 +23,48 @@ type ValueSortColumn struct {
 	Title string          `json:"title"`
 	Cards []ValueSortCard `json:"cards"`
 	Order int             `json:"order"`
+	Limit *int            `json:"limit,omitempty"`
 }
 
+// Version goes up with every change. Send it back with an update to have it
+// refused if someone else has changed the board since.
 type ValueSortBoard struct {
 	Name    string            `json:"name"`
+	Version int64             `json:"version,omitempty"`
 	Columns []ValueSortColumn `json:"columns"`
 }
 
+// How a board's column should look. Set PreviousTitle to rename a column,
+// taking its cards with it.
+type ColumnDefinition struct {
+	Title         string `json:"title"`
+	Order         int    `json:"order"`
+	Limit         *int   `json:"limit,omitempty"`
+	PreviousTitle string `json:"previousTitle,omitempty"`
+}
+
 // An interface to refresent the Model (for mocking in test)
 type ValueSortBoardDataAccessLayer interface {
-	Create(boardName string) (err error)
+	Create(boardName string, deckName string, locale string, owner string) (err error)
 	Get(boardName string) (board ValueSortBoard, err error)
-	Upsert(board ValueSortBoard) (err error)
+	List(owner string) (boards []BoardSummary, err error)
+	Delete(boardName string, owner string) (err error)
+	Rename(boardName string, newName string, owner string) (err error)
+	Clone(boardName string, newName string, owner string) (err error)
+	Upsert(board ValueSortBoard, owner string) (err error)
+	Move(boardName string, move MoveRequest, owner string) (event BoardEvent, err error)
+	Validate(board ValueSortBoard) (errs []error)
+	SetColumns(boardName string, columns []ColumnDefinition, owner string) (err error)
+	History(boardName string) (moves []CardMove, err error)
+	GetAt(boardName string, at time.Time) (board ValueSortBoard, err error)
+	CreateSnapshot(boardName string, name string, owner string) (snapshot Snapshot, err error)
+	ListSnapshots(boardName string) (snapshots []Snapshot, err error)
+	GetSnapshot(boardName string, name string) (snapshot Snapshot, err error)
+	ValidateColumns(columns []ColumnDefinition) (errs []error)
+	GetRounds(boardName string) (progress RoundProgress, err error)
+	SetRounds(boardName string, rounds []Round, owner string) (err error)
+	AdvanceRound(boardName string, owner string) (progress RoundProgress, err error)
+	ValidateRounds(rounds []Round) (errs []error)
 }
 
 // The Model with Database Implementation
@@ -38,117 +72,495 @@ type ValueSortBoardModel struct {
 	DB *pgx.Conn
 }
 
-// Fetch and Assemble Board
+// Fetch and Assemble Board, with its columns in order
 func (model *ValueSortBoardModel) Get(boardName string) (board ValueSortBoard, err error) {
-	stmt := `
-		SELECT board_name, card_body, card_details, column_name
-		FROM value_sort_cards
-		WHERE board_name = $1;
-	`
+	return loadBoard(context.Background(), model.DB, boardName)
+}
 
-	rows, err := model.DB.Query(context.Background(), stmt, boardName)
+func loadBoard(ctx context.Context, q querier, boardName string) (board ValueSortBoard, err error) {
+	board = ValueSortBoard{Columns: []ValueSortColumn{}}
+	err = q.QueryRow(ctx, `SELECT name, version FROM value_sort_boards WHERE name = $1`, boardName).Scan(&board.Name, &board.Version)
 	if err != nil {
 		return ValueSortBoard{}, err
 	}
 
-	colMap := map[string]ValueSortColumn{}
-	columnTitles := []string{
-		"Unsorted",
-		"Not Important",
-		"Somewhat Important",
-		"Important",
-		"Very Important",
-		"Most Important",
+	columnStmt := `
+		SELECT title, position, card_limit
+		FROM value_sort_columns
+		WHERE board_name = $1
+		ORDER BY position, title;
+	`
+	rows, err := q.Query(ctx, columnStmt, boardName)
+	if err != nil {
+		return ValueSortBoard{}, err
 	}
-	for idx, colTitle := range columnTitles {
-		colMap[colTitle] = ValueSortColumn{
-			Title: colTitle,
-			Cards: []ValueSortCard{},
-			Order: idx,
+	columnIdx := map[string]int{}
+	for rows.Next() {
+		column := ValueSortColumn{Cards: []ValueSortCard{}}
+		err = rows.Scan(&column.Title, &column.Order, &column.Limit)
+		if err != nil {
+			rows.Close()
+			return ValueSortBoard{}, err
 		}
+		columnIdx[column.Title] = len(board.Columns)
+		board.Columns = append(board.Columns, column)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return ValueSortBoard{}, rows.Err()
 	}
 
+	cardStmt := `
+		SELECT card_body, card_details, column_name
+		FROM value_sort_cards
+		WHERE board_name = $1
+		ORDER BY position, card_body;
+	`
+	rows, err = q.Query(ctx, cardStmt, boardName)
+	if err != nil {
+		return ValueSortBoard{}, err
+	}
+	defer rows.Close()
 	for rows.Next() {
 		var columnName string
 		var card ValueSortCard
-		err = rows.Scan(&board.Name, &card.Body, &card.Details, &columnName)
+		err = rows.Scan(&card.Body, &card.Details, &columnName)
 		if err != nil {
-			return ValueSortBoard{}, nil
+			return ValueSortBoard{}, err
 		}
+		// the foreign key keeps cards in a defined column
+		if idx, ok := columnIdx[columnName]; ok {
+			board.Columns[idx].Cards = append(board.Columns[idx].Cards, card)
+		}
+	}
 
-		if col, ok := colMap[columnName]; ok {
-			col.Cards = append(col.Cards, card)
-			colMap[columnName] = col
+	return board, rows.Err()
+}
+
+// Create Board w/ Default Columns, dealing the deck's cards into the first in
+// the locale asked for
+func (model *ValueSortBoardModel) Create(boardName string, deckName string, locale string, owner string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deck, err := loadDeck(ctx, tx, deckName)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return webserverutils.NewRequestError(fmt.Sprintf("unknown deck '%s'", deckName))
 		}
+		return err
+	}
+	if locale == "" {
+		locale = deck.DefaultLocale
 	}
 
-	for _, column := range colMap {
-		board.Columns = append(
-			board.Columns,
-			column,
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO value_sort_boards (name, deck_name, locale, owner) VALUES ($1, $2, $3, nullif($4, ''))`,
+		boardName, deck.Name, locale, owner,
+	)
+	if err != nil {
+		return database.TranslateError(err)
+	}
+
+	for idx, title := range DefaultColumns {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO value_sort_columns (board_name, title, position) VALUES ($1, $2, $3)`,
+			boardName, title, idx,
 		)
+		if err != nil {
+			return database.TranslateError(err)
+		}
 	}
 
-	return board, err
+	cards := []ValueSortCard{}
+	for _, card := range deck.Cards {
+		text := card.TextFor(locale, deck.DefaultLocale)
+		cards = append(cards, ValueSortCard{Body: text.Body, Details: text.Details})
+	}
+	err = replaceCards(ctx, tx, ValueSortBoard{
+		Name:    boardName,
+		Version: 1,
+		Columns: []ValueSortColumn{{Title: DefaultColumns[0], Cards: cards}},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// Create Board w/ Default Cards
-func (model *ValueSortBoardModel) Create(boardName string) (err error) {
-	var initialData []ValueSortColumn
-	err = json.Unmarshal([]byte(InitialData), &initialData)
+// Replaces the board's cards with the ones sent, in the order they're listed.
+// Cards can only go in columns the board defines, and no further than their
+// limit or the current round's capacity. Connected clients are told to refetch the board.
+func (model *ValueSortBoardModel) Upsert(board ValueSortBoard, owner string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	for _, col := range initialData {
-		for _, card := range col.Cards {
-			stmt := `
-				INSERT INTO value_sort_cards (board_name, card_body, card_details, column_name) 
-				VALUES ($1, $2, $3, $4)
-				RETURNING board_name
-			`
-
-			var result string
-			err := model.DB.QueryRow(
-				context.Background(),
-				stmt,
-				boardName, card.Body, card.Details, col.Title,
-			).Scan(&result)
-
-			if err != nil {
-				return database.TranslateError(err)
-			}
+	err = checkOwner(ctx, tx, board.Name, owner)
+	if err != nil {
+		return err
+	}
+	version, err := bumpVersion(ctx, tx, board.Name)
+	if err != nil {
+		return err
+	}
+	if board.Version != 0 && board.Version != version-1 {
+		return ErrStaleVersion
+	}
+	board.Version = version
+	limits, err := columnLimits(ctx, tx, board.Name)
+	if err != nil {
+		return err
+	}
+	// in guided mode the current round can hold columns to less
+	limits, err = roundLimits(ctx, tx, board.Name, limits)
+	if err != nil {
+		return err
+	}
+	errMsgs := []string{}
+	counts := map[string]int{}
+	for _, col := range board.Columns {
+		if _, ok := limits[col.Title]; !ok {
+			errMsgs = append(errMsgs, fmt.Sprintf("unknown column '%s'", col.Title))
 		}
+		counts[col.Title] += len(col.Cards)
+	}
+	if len(errMsgs) > 0 {
+		return webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+	}
+	err = limitErrors(counts, limits)
+	if err != nil {
+		return err
 	}
 
-	return err
+	err = replaceCards(ctx, tx, board)
+	if err != nil {
+		return err
+	}
+	err = notify(ctx, tx, BoardEvent{Type: EventBoard, Board: board.Name, Version: version})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (model *ValueSortBoardModel) Upsert(board ValueSortBoard) (err error) {
+// Marks the board as changed, locking it for the rest of the transaction, and
+// returns its new version
+func bumpVersion(ctx context.Context, tx pgx.Tx, boardName string) (version int64, err error) {
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE value_sort_boards SET version = version + 1, date_modified = now() WHERE name = $1 RETURNING version`,
+		boardName,
+	).Scan(&version)
+	return version, err
+}
+
+// Writes the board's cards in one statement, dropping any it no longer has and
+// logging what moved. Cards that change are stamped with the board's version.
+func replaceCards(ctx context.Context, tx pgx.Tx, board ValueSortBoard) error {
+	// empty rather than nil, so an empty board clears every card
+	bodies, details, columns, positions := []string{}, []string{}, []string{}, []int{}
 	for _, col := range board.Columns {
+		for position, card := range col.Cards {
+			bodies = append(bodies, card.Body)
+			details = append(details, card.Details)
+			columns = append(columns, col.Title)
+			positions = append(positions, position)
+		}
+	}
+
+	// every card that changes column, arrives or leaves is logged as a move
+	stmt := `
+		WITH incoming AS (
+			SELECT * FROM unnest($2::text[], $3::text[], $4::text[], $5::int[])
+				AS t (card_body, card_details, column_name, position)
+		), previous AS (
+			SELECT card_body, column_name FROM value_sort_cards WHERE board_name = $1
+		), moved AS (
+			INSERT INTO value_sort_moves (board_name, card_body, card_details, from_column, to_column, position)
+			SELECT $1, coalesce(i.card_body, p.card_body), i.card_details, p.column_name, i.column_name, i.position
+			FROM incoming i
+			FULL JOIN previous p ON p.card_body = i.card_body
+			WHERE i.column_name IS DISTINCT FROM p.column_name
+		), removed AS (
+			DELETE FROM value_sort_cards c
+			WHERE c.board_name = $1 AND c.card_body <> ALL($2::text[])
+		)
+		INSERT INTO value_sort_cards (board_name, card_body, card_details, column_name, position, version)
+		SELECT $1, card_body, card_details, column_name, position, $6 FROM incoming
+		ON CONFLICT (board_name, card_body)
+		DO
+			UPDATE SET
+				card_details = EXCLUDED.card_details,
+				column_name = EXCLUDED.column_name,
+				position = EXCLUDED.position,
+				version = CASE
+					WHEN (value_sort_cards.card_details, value_sort_cards.column_name, value_sort_cards.position)
+						IS DISTINCT FROM (EXCLUDED.card_details, EXCLUDED.column_name, EXCLUDED.position)
+					THEN EXCLUDED.version
+					ELSE value_sort_cards.version
+				END
+	`
+	_, err := tx.Exec(ctx, stmt, board.Name, bodies, details, columns, positions, board.Version)
+	if err != nil {
+		fmt.Println(err)
+		return database.TranslateError(err)
+	}
+	return nil
+}
+
+func (model *ValueSortBoardModel) Validate(board ValueSortBoard) (errs []error) {
+	errs = []error{}
+	if strings.TrimSpace(board.Name) == "" {
+		errs = append(errs, errors.New("missing name"))
+	}
+	seen := map[string]bool{}
+	columns := map[string]bool{}
+	for _, col := range board.Columns {
+		if columns[col.Title] {
+			errs = append(errs, fmt.Errorf("column '%s' appears more than once", col.Title))
+		}
+		columns[col.Title] = true
 		for _, card := range col.Cards {
-			// TODO: can accidentally create new tables
-			stmt := `
-				INSERT INTO value_sort_cards (board_name, card_body, card_details, column_name) 
-				VALUES ($1, $2, $3, $4)
-				ON CONFLICT (board_name, card_body) 
-				DO 
-					UPDATE SET column_name = $4
-				RETURNING board_name
-			`
-
-			var result string
-			err := model.DB.QueryRow(
-				context.Background(),
-				stmt,
-				board.Name, card.Body, card.Details, col.Title,
-			).Scan(&result)
-
-			if err != nil {
-				fmt.Println(err)
-				return database.TranslateError(err)
+			if strings.TrimSpace(card.Body) == "" {
+				errs = append(errs, fmt.Errorf("card in column '%s' is missing a body", col.Title))
+			} else if seen[card.Body] {
+				errs = append(errs, fmt.Errorf("card '%s' appears more than once", card.Body))
 			}
+			seen[card.Body] = true
 		}
 	}
-	return nil
+	return errs
+}
+
+// Replaces the board's column definitions. Columns left out are removed,
+// which is refused while they still hold cards.
+func (model *ValueSortBoardModel) SetColumns(boardName string, columns []ColumnDefinition, owner string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return err
+	}
+	version, err := bumpVersion(ctx, tx, boardName)
+	if err != nil {
+		return err
+	}
+	existing, err := columnLimits(ctx, tx, boardName)
+	if err != nil {
+		return err
+	}
+
+	// renames go first so the cards follow their column
+	for _, col := range columns {
+		if col.PreviousTitle == "" || col.PreviousTitle == col.Title {
+			continue
+		}
+		if _, ok := existing[col.PreviousTitle]; !ok {
+			return webserverutils.NewRequestError(fmt.Sprintf("unknown column '%s'", col.PreviousTitle))
+		}
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE value_sort_columns SET title = $3 WHERE board_name = $1 AND title = $2`,
+			boardName, col.PreviousTitle, col.Title,
+		)
+		if err != nil {
+			return database.TranslateError(err)
+		}
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE value_sort_round_columns SET column_name = $3 WHERE board_name = $1 AND column_name = $2`,
+			boardName, col.PreviousTitle, col.Title,
+		)
+		if err != nil {
+			return database.TranslateError(err)
+		}
+		// a rename isn't a move, so the history follows it too
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE value_sort_moves
+			SET from_column = CASE WHEN from_column = $2 THEN $3 ELSE from_column END,
+				to_column = CASE WHEN to_column = $2 THEN $3 ELSE to_column END
+			WHERE board_name = $1 AND (from_column = $2 OR to_column = $2)`,
+			boardName, col.PreviousTitle, col.Title,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	titles := []string{}
+	limits := map[string]*int{}
+	for _, col := range columns {
+		titles = append(titles, col.Title)
+		limits[col.Title] = col.Limit
+	}
+	var stranded []string
+	err = tx.QueryRow(
+		ctx,
+		`SELECT coalesce(array_agg(DISTINCT column_name), '{}') FROM value_sort_cards WHERE board_name = $1 AND NOT column_name = ANY($2)`,
+		boardName, titles,
+	).Scan(&stranded)
+	if err != nil {
+		return err
+	}
+	if len(stranded) > 0 {
+		return webserverutils.NewRequestError(fmt.Sprintf("columns still holding cards can't be removed: %s", strings.Join(stranded, ", ")))
+	}
+	err = tx.QueryRow(
+		ctx,
+		`SELECT coalesce(array_agg(DISTINCT column_name), '{}') FROM value_sort_round_columns WHERE board_name = $1 AND NOT column_name = ANY($2)`,
+		boardName, titles,
+	).Scan(&stranded)
+	if err != nil {
+		return err
+	}
+	if len(stranded) > 0 {
+		return webserverutils.NewRequestError(fmt.Sprintf("columns used by the board's rounds can't be removed: %s", strings.Join(stranded, ", ")))
+	}
+	_, err = tx.Exec(
+		ctx,
+		`DELETE FROM value_sort_columns WHERE board_name = $1 AND NOT title = ANY($2)`,
+		boardName, titles,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		stmt := `
+			INSERT INTO value_sort_columns (board_name, title, position, card_limit)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (board_name, title)
+			DO
+				UPDATE SET position = $3, card_limit = $4
+		`
+		_, err = tx.Exec(ctx, stmt, boardName, col.Title, col.Order, col.Limit)
+		if err != nil {
+			return database.TranslateError(err)
+		}
+	}
+
+	limits, err = roundLimits(ctx, tx, boardName, limits)
+	if err != nil {
+		return err
+	}
+	err = checkLimits(ctx, tx, boardName, limits)
+	if err != nil {
+		return err
+	}
+	err = notify(ctx, tx, BoardEvent{Type: EventBoard, Board: boardName, Version: version})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (model *ValueSortBoardModel) ValidateColumns(columns []ColumnDefinition) (errs []error) {
+	errs = []error{}
+	if len(columns) == 0 {
+		errs = append(errs, errors.New("a board needs at least one column"))
+	}
+	titles := map[string]bool{}
+	orders := map[int]bool{}
+	renamed := map[string]bool{}
+	for _, col := range columns {
+		if strings.TrimSpace(col.Title) == "" {
+			errs = append(errs, errors.New("column is missing a title"))
+		} else if titles[col.Title] {
+			errs = append(errs, fmt.Errorf("column '%s' appears more than once", col.Title))
+		}
+		titles[col.Title] = true
+		if orders[col.Order] {
+			errs = append(errs, fmt.Errorf("more than one column has order %d", col.Order))
+		}
+		orders[col.Order] = true
+		if col.Limit != nil && *col.Limit < 1 {
+			errs = append(errs, fmt.Errorf("column '%s' needs a limit of at least 1", col.Title))
+		}
+		if col.PreviousTitle != "" {
+			if renamed[col.PreviousTitle] {
+				errs = append(errs, fmt.Errorf("column '%s' is renamed more than once", col.PreviousTitle))
+			}
+			renamed[col.PreviousTitle] = true
+		}
+	}
+	return errs
+}
+
+// The board's columns by title, with their card limits
+func columnLimits(ctx context.Context, tx pgx.Tx, boardName string) (map[string]*int, error) {
+	rows, err := tx.Query(ctx, `SELECT title, card_limit FROM value_sort_columns WHERE board_name = $1`, boardName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	limits := map[string]*int{}
+	for rows.Next() {
+		var title string
+		var limit *int
+		err = rows.Scan(&title, &limit)
+		if err != nil {
+			return nil, err
+		}
+		limits[title] = limit
+	}
+	return limits, rows.Err()
+}
+
+func checkLimits(ctx context.Context, tx pgx.Tx, boardName string, limits map[string]*int) error {
+	counts, err := cardCounts(ctx, tx, boardName)
+	if err != nil {
+		return err
+	}
+	return limitErrors(counts, limits)
+}
+
+// How many cards each of the board's columns holds
+func cardCounts(ctx context.Context, q querier, boardName string) (map[string]int, error) {
+	rows, err := q.Query(ctx, `SELECT column_name, count(*) FROM value_sort_cards WHERE board_name = $1 GROUP BY column_name`, boardName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var title string
+		var count int
+		err = rows.Scan(&title, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[title] = count
+	}
+	return counts, rows.Err()
+}
+
+func limitErrors(counts map[string]int, limits map[string]*int) error {
+	errMsgs := []string{}
+	for title, limit := range limits {
+		if limit != nil && counts[title] > *limit {
+			errMsgs = append(errMsgs, fmt.Sprintf("column '%s' holds at most %d cards", title, *limit))
+		}
+	}
+	if len(errMsgs) == 0 {
+		return nil
+	}
+	sort.Strings(errMsgs)
+	return webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
 }
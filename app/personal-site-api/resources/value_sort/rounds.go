@@ -0,0 +1,321 @@
+package valuesort
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+const (
+	// Cards go anywhere, as long as columns' own limits allow
+	ModeFree = "free"
+	// The board is sorted in rounds, each narrowing things down further
+	ModeGuided = "guided"
+)
+
+// What a round asks of a column. Capacity is how many cards it can hold while
+// the round is on, and Required how many it needs for the round to finish.
+type RoundColumn struct {
+	Column   string `json:"column"`
+	Capacity *int   `json:"capacity,omitempty"`
+	Required *int   `json:"required,omitempty"`
+}
+
+// e.g. {"title": "Pick your top 10", "columns": [{"column": "Top 10", "capacity": 10, "required": 10}]}
+type Round struct {
+	Title   string        `json:"title"`
+	Columns []RoundColumn `json:"columns"`
+}
+
+// Where a board is up to. Current indexes Rounds, and Unmet lists what's
+// stopping the current round from finishing.
+type RoundProgress struct {
+	Mode     string   `json:"mode"`
+	Current  int      `json:"current"`
+	Complete bool     `json:"complete"`
+	Rounds   []Round  `json:"rounds"`
+	Unmet    []string `json:"unmet"`
+}
+
+func (model *ValueSortBoardModel) GetRounds(boardName string) (progress RoundProgress, err error) {
+	ctx := context.Background()
+	progress, err = loadRounds(ctx, model.DB, boardName)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	counts, err := cardCounts(ctx, model.DB, boardName)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	progress.Unmet = progress.unmet(counts)
+	return progress, nil
+}
+
+// Replaces the board's rounds and starts again from the first. No rounds puts
+// the board back in free mode.
+func (model *ValueSortBoardModel) SetRounds(boardName string, rounds []Round, owner string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return err
+	}
+	version, err := bumpVersion(ctx, tx, boardName)
+	if err != nil {
+		return err
+	}
+	limits, err := columnLimits(ctx, tx, boardName)
+	if err != nil {
+		return err
+	}
+	errMsgs := []string{}
+	for _, round := range rounds {
+		for _, col := range round.Columns {
+			if _, ok := limits[col.Column]; !ok {
+				errMsgs = append(errMsgs, fmt.Sprintf("unknown column '%s'", col.Column))
+			}
+		}
+	}
+	if len(errMsgs) > 0 {
+		return webserverutils.NewRequestError(strings.Join(errMsgs, ", "))
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM value_sort_rounds WHERE board_name = $1`, boardName)
+	if err != nil {
+		return err
+	}
+	for number, round := range rounds {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO value_sort_rounds (board_name, number, title) VALUES ($1, $2, $3)`,
+			boardName, number, round.Title,
+		)
+		if err != nil {
+			return database.TranslateError(err)
+		}
+		for _, col := range round.Columns {
+			_, err = tx.Exec(
+				ctx,
+				`INSERT INTO value_sort_round_columns (board_name, round_number, column_name, capacity, required)
+				VALUES ($1, $2, $3, $4, $5)`,
+				boardName, number, col.Column, col.Capacity, col.Required,
+			)
+			if err != nil {
+				return database.TranslateError(err)
+			}
+		}
+	}
+	_, err = tx.Exec(ctx, `UPDATE value_sort_boards SET current_round = 0 WHERE name = $1`, boardName)
+	if err != nil {
+		return err
+	}
+
+	// the board has to fit the first round as it stands
+	limits, err = roundLimits(ctx, tx, boardName, limits)
+	if err != nil {
+		return err
+	}
+	err = checkLimits(ctx, tx, boardName, limits)
+	if err != nil {
+		return err
+	}
+	err = notify(ctx, tx, BoardEvent{Type: EventBoard, Board: boardName, Version: version})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Finishes the current round, as long as the board meets everything it asks
+func (model *ValueSortBoardModel) AdvanceRound(boardName string, owner string) (progress RoundProgress, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	version, err := bumpVersion(ctx, tx, boardName)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	progress, err = loadRounds(ctx, tx, boardName)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	if progress.Mode != ModeGuided {
+		return RoundProgress{}, webserverutils.NewRequestError("the board isn't sorted in rounds")
+	}
+	if progress.Complete {
+		return RoundProgress{}, webserverutils.NewRequestError("every round is already finished")
+	}
+	counts, err := cardCounts(ctx, tx, boardName)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	if unmet := progress.unmet(counts); len(unmet) > 0 {
+		return RoundProgress{}, webserverutils.NewRequestError(strings.Join(unmet, ", "))
+	}
+
+	progress.Current++
+	progress.Complete = progress.Current >= len(progress.Rounds)
+	_, err = tx.Exec(ctx, `UPDATE value_sort_boards SET current_round = $2 WHERE name = $1`, boardName, progress.Current)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	progress.Unmet = progress.unmet(counts)
+	err = notify(ctx, tx, BoardEvent{Type: EventBoard, Board: boardName, Version: version})
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	return progress, tx.Commit(ctx)
+}
+
+func (model *ValueSortBoardModel) ValidateRounds(rounds []Round) (errs []error) {
+	errs = []error{}
+	for idx, round := range rounds {
+		name := fmt.Sprintf("round %d", idx+1)
+		if strings.TrimSpace(round.Title) == "" {
+			errs = append(errs, fmt.Errorf("%s is missing a title", name))
+		}
+		if len(round.Columns) == 0 {
+			errs = append(errs, fmt.Errorf("%s needs at least one column", name))
+		}
+		seen := map[string]bool{}
+		for _, col := range round.Columns {
+			if strings.TrimSpace(col.Column) == "" {
+				errs = append(errs, fmt.Errorf("%s has a column without a name", name))
+				continue
+			}
+			if seen[col.Column] {
+				errs = append(errs, fmt.Errorf("column '%s' appears more than once in %s", col.Column, name))
+			}
+			seen[col.Column] = true
+			if col.Capacity == nil && col.Required == nil {
+				errs = append(errs, fmt.Errorf("column '%s' in %s needs a capacity or a required count", col.Column, name))
+			}
+			if col.Capacity != nil && *col.Capacity < 0 {
+				errs = append(errs, fmt.Errorf("column '%s' in %s can't have a negative capacity", col.Column, name))
+			}
+			if col.Required != nil && *col.Required < 0 {
+				errs = append(errs, fmt.Errorf("column '%s' in %s can't require a negative count", col.Column, name))
+			}
+			if col.Capacity != nil && col.Required != nil && *col.Required > *col.Capacity {
+				errs = append(errs, fmt.Errorf("column '%s' in %s requires more cards than it can hold", col.Column, name))
+			}
+		}
+	}
+	return errs
+}
+
+func loadRounds(ctx context.Context, q querier, boardName string) (progress RoundProgress, err error) {
+	err = q.QueryRow(ctx, `SELECT current_round FROM value_sort_boards WHERE name = $1`, boardName).Scan(&progress.Current)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+
+	rows, err := q.Query(
+		ctx,
+		`SELECT r.number, r.title, c.column_name, c.capacity, c.required
+		FROM value_sort_rounds r
+		LEFT JOIN value_sort_round_columns c ON c.board_name = r.board_name AND c.round_number = r.number
+		WHERE r.board_name = $1
+		ORDER BY r.number, c.column_name`,
+		boardName,
+	)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	defer rows.Close()
+	progress.Rounds = []Round{}
+	for rows.Next() {
+		var number int
+		var title string
+		var column *string
+		var col RoundColumn
+		err = rows.Scan(&number, &title, &column, &col.Capacity, &col.Required)
+		if err != nil {
+			return RoundProgress{}, err
+		}
+		if number >= len(progress.Rounds) {
+			progress.Rounds = append(progress.Rounds, Round{Title: title, Columns: []RoundColumn{}})
+		}
+		if column != nil {
+			col.Column = *column
+			progress.Rounds[len(progress.Rounds)-1].Columns = append(progress.Rounds[len(progress.Rounds)-1].Columns, col)
+		}
+	}
+	if rows.Err() != nil {
+		return RoundProgress{}, rows.Err()
+	}
+
+	progress.Mode = ModeFree
+	if len(progress.Rounds) > 0 {
+		progress.Mode = ModeGuided
+	}
+	progress.Complete = progress.Mode == ModeGuided && progress.Current >= len(progress.Rounds)
+	return progress, nil
+}
+
+// What the current round still needs, given how many cards each column holds
+func (progress RoundProgress) unmet(counts map[string]int) []string {
+	unmet := []string{}
+	if progress.Mode != ModeGuided || progress.Complete {
+		return unmet
+	}
+	for _, col := range progress.Rounds[progress.Current].Columns {
+		count := counts[col.Column]
+		if col.Capacity != nil && count > *col.Capacity {
+			unmet = append(unmet, fmt.Sprintf("column '%s' holds at most %d cards this round, not %d", col.Column, *col.Capacity, count))
+		}
+		if col.Required != nil && count != *col.Required {
+			unmet = append(unmet, fmt.Sprintf("column '%s' needs %d cards to finish this round, not %d", col.Column, *col.Required, count))
+		}
+	}
+	return unmet
+}
+
+// Tightens the columns' own limits to the current round's capacities
+func roundLimits(ctx context.Context, tx pgx.Tx, boardName string, limits map[string]*int) (map[string]*int, error) {
+	rows, err := tx.Query(
+		ctx,
+		`SELECT c.column_name, c.capacity
+		FROM value_sort_round_columns c
+		JOIN value_sort_boards b ON b.name = c.board_name AND b.current_round = c.round_number
+		WHERE c.board_name = $1 AND c.capacity IS NOT NULL`,
+		boardName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tightened := map[string]*int{}
+	for title, limit := range limits {
+		tightened[title] = limit
+	}
+	for rows.Next() {
+		var title string
+		var capacity int
+		err = rows.Scan(&title, &capacity)
+		if err != nil {
+			return nil, err
+		}
+		if limit, ok := tightened[title]; ok && (limit == nil || capacity < *limit) {
+			tightened[title] = &capacity
+		}
+	}
+	return tightened, rows.Err()
+}
@@ -0,0 +1,163 @@
+package valuesort
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/internal/webserverutils"
+)
+
+type MockRoundsModel struct {
+	MockBoardModel
+	progress *RoundProgress
+	counts   map[string]int
+}
+
+func (model MockRoundsModel) GetRounds(boardName string) (RoundProgress, error) {
+	if _, ok := model.boards[boardName]; !ok {
+		return RoundProgress{}, errors.New("no rows in result set")
+	}
+	progress := *model.progress
+	progress.Unmet = progress.unmet(model.counts)
+	return progress, nil
+}
+func (model MockRoundsModel) SetRounds(boardName string, rounds []Round, owner string) error {
+	if _, ok := model.boards[boardName]; !ok {
+		return errors.New("no rows in result set")
+	}
+	*model.progress = RoundProgress{Mode: ModeFree, Rounds: rounds}
+	if len(rounds) > 0 {
+		model.progress.Mode = ModeGuided
+	}
+	return nil
+}
+func (model MockRoundsModel) AdvanceRound(boardName string, owner string) (RoundProgress, error) {
+	progress, err := model.GetRounds(boardName)
+	if err != nil {
+		return RoundProgress{}, err
+	}
+	if len(progress.Unmet) > 0 {
+		return RoundProgress{}, webserverutils.NewRequestError(strings.Join(progress.Unmet, ", "))
+	}
+	model.progress.Current++
+	model.progress.Complete = model.progress.Current >= len(model.progress.Rounds)
+	return model.GetRounds(boardName)
+}
+
+func classicRounds() []Round {
+	return []Round{
+		{Title: "Sort every card", Columns: []RoundColumn{{Column: "Unsorted", Required: intPtr(0)}}},
+		{Title: "Pick your top 10", Columns: []RoundColumn{{Column: "Top 10", Capacity: intPtr(10), Required: intPtr(10)}}},
+	}
+}
+
+func TestRoundProgressUnmet(t *testing.T) {
+	progress := RoundProgress{Mode: ModeGuided, Rounds: classicRounds()}
+	if unmet := progress.unmet(map[string]int{"Unsorted": 3}); len(unmet) != 1 || unmet[0] != "column 'Unsorted' needs 0 cards to finish this round, not 3" {
+		t.Errorf("expected the unsorted cards to be reported but received %v", unmet)
+	}
+	if unmet := progress.unmet(map[string]int{"Important": 40}); len(unmet) != 0 {
+		t.Errorf("expected nothing unmet but received %v", unmet)
+	}
+
+	progress.Current = 1
+	unmet := progress.unmet(map[string]int{"Top 10": 12})
+	if len(unmet) != 2 || !strings.Contains(unmet[0], "holds at most 10 cards this round") {
+		t.Errorf("expected the capacity and count to be reported but received %v", unmet)
+	}
+
+	progress.Current, progress.Complete = 2, true
+	if unmet := progress.unmet(map[string]int{"Top 10": 12}); len(unmet) != 0 {
+		t.Errorf("expected a finished board to have nothing unmet but received %v", unmet)
+	}
+}
+
+func TestValidateRounds(t *testing.T) {
+	model := &ValueSortBoardModel{}
+	if errs := model.ValidateRounds(classicRounds()); len(errs) != 0 {
+		t.Errorf("expected the rounds to be valid but received %v", errs)
+	}
+	if errs := model.ValidateRounds([]Round{}); len(errs) != 0 {
+		t.Errorf("expected no rounds to be valid but received %v", errs)
+	}
+
+	cases := []Round{
+		{Title: " ", Columns: []RoundColumn{{Column: "Unsorted", Required: intPtr(0)}}},
+		{Title: "Empty", Columns: []RoundColumn{}},
+		{Title: "Unbounded", Columns: []RoundColumn{{Column: "Unsorted"}}},
+		{Title: "Twice", Columns: []RoundColumn{{Column: "A", Required: intPtr(1)}, {Column: "A", Required: intPtr(2)}}},
+		{Title: "Negative", Columns: []RoundColumn{{Column: "A", Capacity: intPtr(-1)}}},
+		{Title: "Overfull", Columns: []RoundColumn{{Column: "A", Capacity: intPtr(5), Required: intPtr(10)}}},
+	}
+	for _, round := range cases {
+		if errs := model.ValidateRounds([]Round{round}); len(errs) != 1 {
+			t.Errorf("expected one error for %s but received %v", round.Title, errs)
+		}
+	}
+}
+
+func TestRoundsHandlers(t *testing.T) {
+	model := MockRoundsModel{
+		MockBoardModel: mockBoards(),
+		progress:       &RoundProgress{Mode: ModeFree, Rounds: []Round{}},
+		counts:         map[string]int{"Unsorted": 1},
+	}
+
+	body, err := json.Marshal(classicRounds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := put(t, UpdateRoundsHandler(model), "some-board", string(body))
+	if rr.Code != 200 {
+		t.Fatalf("expected status code %d but received %d - %s", 200, rr.Code, rr.Body.String())
+	}
+	var progress RoundProgress
+	err = json.Unmarshal(rr.Body.Bytes(), &progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Mode != ModeGuided || progress.Current != 0 || len(progress.Unmet) != 1 {
+		t.Errorf("expected the first round to be unfinished but received %+v", progress)
+	}
+
+	advance := func(boardName string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/api/v1/value-sort/boards/"+boardName+"/rounds/advance", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		AdvanceRoundHandler(model).ServeHTTP(rr, mux.SetURLVars(req, map[string]string{"boardName": boardName}))
+		return rr
+	}
+	if rr := advance("some-board"); rr.Code != 422 || !strings.Contains(rr.Body.String(), "'Unsorted' needs 0 cards") {
+		t.Errorf("expected an unfinished round to be refused but received %d - %s", rr.Code, rr.Body.String())
+	}
+	model.counts["Unsorted"] = 0
+	if rr := advance("some-board"); rr.Code != 200 || model.progress.Current != 1 {
+		t.Errorf("expected to move on to the second round but received %d - %s", rr.Code, rr.Body.String())
+	}
+	if rr := advance("missing-board"); rr.Code != 404 {
+		t.Errorf("expected status code %d but received %d", 404, rr.Code)
+	}
+
+	cases := []struct {
+		boardName    string
+		body         string
+		expectedCode int
+	}{
+		{"some-board", `[{"title": "Round", "columns": []}]`, 422},
+		{"some-board", `[{"title": "Round", "columns": [{"column": "Unsorted", "required": 0}], "colour": "red"}]`, 422},
+		{"missing-board", `[]`, 404},
+	}
+	for _, c := range cases {
+		rr := put(t, UpdateRoundsHandler(model), c.boardName, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d", c.expectedCode, c.body, rr.Code)
+		}
+	}
+}
@@ -1,9 +1,40 @@
 package valuesort
 
-import "github.com/gorilla/mux"
+import (
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+)
 
-func InitializeRoutes(router *mux.Router, model ValueSortBoardDataAccessLayer) {
+func InitializeRoutes(router *mux.Router, model ValueSortBoardDataAccessLayer, decks DeckDataAccessLayer, hub *BoardHub) {
+	// every write to an existing board checks the X-Board-Owner header against
+	// the board's owner; reads, creating and cloning don't
+	router.HandleFunc("/boards", ListBoardsHandler(model)).Methods("GET")
 	router.HandleFunc("/boards", CreateBoardHandler(model)).Methods("POST")
 	router.HandleFunc("/boards/{boardName}", GetBoardHandler(model)).Methods("GET")
 	router.HandleFunc("/boards/{boardName}", UpdateBoardHandler(model)).Methods("PUT")
+	router.HandleFunc("/boards/{boardName}", DeleteBoardHandler(model)).Methods("DELETE")
+	router.HandleFunc("/boards/{boardName}/rename", RenameBoardHandler(model)).Methods("POST")
+	router.HandleFunc("/boards/{boardName}/clone", CloneBoardHandler(model)).Methods("POST")
+	router.HandleFunc("/boards/{boardName}/moves", MoveCardHandler(model)).Methods("POST")
+	router.HandleFunc("/boards/{boardName}/events", BoardEventsHandler(model, hub)).Methods("GET")
+	router.HandleFunc("/boards/{boardName}/history", GetBoardHistoryHandler(model)).Methods("GET")
+	router.HandleFunc("/boards/{boardName}/export", ExportBoardHandler(model)).Methods("GET")
+	router.HandleFunc("/boards/{boardName}/snapshots", GetSnapshotsHandler(model)).Methods("GET")
+	router.HandleFunc("/boards/{boardName}/snapshots", CreateSnapshotHandler(model)).Methods("POST")
+	router.HandleFunc("/boards/{boardName}/snapshots/{snapshotName}", GetSnapshotHandler(model)).Methods("GET")
+	router.HandleFunc("/boards/{boardName}/columns", UpdateColumnsHandler(model)).Methods("PUT")
+	router.HandleFunc("/boards/{boardName}/rounds", GetRoundsHandler(model)).Methods("GET")
+	router.HandleFunc("/boards/{boardName}/rounds", UpdateRoundsHandler(model)).Methods("PUT")
+	router.HandleFunc("/boards/{boardName}/rounds/advance", AdvanceRoundHandler(model)).Methods("POST")
+	router.HandleFunc("/compare", CompareBoardsHandler(model)).Methods("GET")
+
+	// decks are shared by every board, so only the site owner changes them
+	router.HandleFunc("/decks", GetDecksHandler(decks)).Methods("GET")
+	router.HandleFunc("/decks", middleware.AuthMiddleware(CreateDeckHandler(decks))).Methods("POST")
+	router.HandleFunc("/decks/{deckName}", GetDeckHandler(decks)).Methods("GET")
+	router.HandleFunc("/decks/{deckName}", middleware.AuthMiddleware(UpdateDeckHandler(decks))).Methods("PUT")
+	router.HandleFunc("/decks/{deckName}", middleware.AuthMiddleware(DeleteDeckHandler(decks))).Methods("DELETE")
+	router.HandleFunc("/decks/{deckName}/cards", middleware.AuthMiddleware(CreateDeckCardHandler(decks))).Methods("POST")
+	router.HandleFunc("/decks/{deckName}/cards/{cardKey}", middleware.AuthMiddleware(UpdateDeckCardHandler(decks))).Methods("PUT")
+	router.HandleFunc("/decks/{deckName}/cards/{cardKey}", middleware.AuthMiddleware(DeleteDeckCardHandler(decks))).Methods("DELETE")
 }
@@ -0,0 +1,197 @@
+package valuesort
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+)
+
+const maxSnapshotNameLength = 100
+
+// A board's state saved under a name, to compare against later
+type Snapshot struct {
+	BoardName   string          `json:"boardName"`
+	Name        string          `json:"name"`
+	DateCreated time.Time       `json:"dateCreated"`
+	Board       *ValueSortBoard `json:"board,omitempty"`
+}
+
+// Saves the board as it is now
+func (model *ValueSortBoardModel) CreateSnapshot(boardName string, name string, owner string) (snapshot Snapshot, err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = checkOwner(ctx, tx, boardName, owner)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	board, err := loadBoard(ctx, tx, boardName)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot = Snapshot{BoardName: board.Name, Name: name, Board: &board}
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO value_sort_snapshots (board_name, name, state) VALUES ($1, $2, $3) RETURNING date_created`,
+		board.Name, name, board,
+	).Scan(&snapshot.DateCreated)
+	if err != nil {
+		return Snapshot{}, database.TranslateError(err)
+	}
+	return snapshot, tx.Commit(ctx)
+}
+
+// The board's snapshots, newest first, without their boards
+func (model *ValueSortBoardModel) ListSnapshots(boardName string) (snapshots []Snapshot, err error) {
+	ctx := context.Background()
+	var name string
+	err = model.DB.QueryRow(ctx, `SELECT name FROM value_sort_boards WHERE name = $1`, boardName).Scan(&name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := model.DB.Query(
+		ctx,
+		`SELECT board_name, name, date_created FROM value_sort_snapshots WHERE board_name = $1 ORDER BY date_created DESC, name`,
+		boardName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	snapshots = []Snapshot{}
+	for rows.Next() {
+		var snapshot Snapshot
+		err = rows.Scan(&snapshot.BoardName, &snapshot.Name, &snapshot.DateCreated)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (model *ValueSortBoardModel) GetSnapshot(boardName string, name string) (snapshot Snapshot, err error) {
+	snapshot.Board = &ValueSortBoard{}
+	err = model.DB.QueryRow(
+		context.Background(),
+		`SELECT board_name, name, date_created, state FROM value_sort_snapshots WHERE board_name = $1 AND name = $2`,
+		boardName, name,
+	).Scan(&snapshot.BoardName, &snapshot.Name, &snapshot.DateCreated, snapshot.Board)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func ValidateSnapshotName(name string) []error {
+	errs := []error{}
+	if strings.TrimSpace(name) == "" {
+		errs = append(errs, errors.New("missing name"))
+	} else if utf8.RuneCountInString(name) > maxSnapshotNameLength {
+		errs = append(errs, fmt.Errorf("name can be at most %d characters", maxSnapshotNameLength))
+	}
+	return errs
+}
+
+// A card's column in each of the boards compared, and how many columns
+// further along the board (or back, if negative) it ended up
+type CardChange struct {
+	CardBody string `json:"cardBody"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Change   int    `json:"change"`
+}
+
+type Comparison struct {
+	MovedUp   []CardChange `json:"movedUp"`
+	MovedDown []CardChange `json:"movedDown"`
+	Unchanged []CardChange `json:"unchanged"`
+	Added     []string     `json:"added"`
+	Removed   []string     `json:"removed"`
+}
+
+// Reports how each card's column changed between two boards, matching cards
+// by their body. Columns are ranked by order, so the boards don't need the
+// same columns.
+func CompareBoards(from ValueSortBoard, to ValueSortBoard) Comparison {
+	comparison := Comparison{
+		MovedUp:   []CardChange{},
+		MovedDown: []CardChange{},
+		Unchanged: []CardChange{},
+		Added:     []string{},
+		Removed:   []string{},
+	}
+	before := rankCards(from)
+	after := rankCards(to)
+
+	for body, was := range before {
+		now, ok := after[body]
+		if !ok {
+			comparison.Removed = append(comparison.Removed, body)
+			continue
+		}
+		change := CardChange{CardBody: body, From: was.column, To: now.column, Change: now.rank - was.rank}
+		switch {
+		case change.Change > 0:
+			comparison.MovedUp = append(comparison.MovedUp, change)
+		case change.Change < 0:
+			comparison.MovedDown = append(comparison.MovedDown, change)
+		default:
+			comparison.Unchanged = append(comparison.Unchanged, change)
+		}
+	}
+	for body := range after {
+		if _, ok := before[body]; !ok {
+			comparison.Added = append(comparison.Added, body)
+		}
+	}
+
+	// biggest moves first
+	sort.Slice(comparison.MovedUp, func(i, j int) bool {
+		return lessChange(comparison.MovedUp[i], comparison.MovedUp[j], 1)
+	})
+	sort.Slice(comparison.MovedDown, func(i, j int) bool {
+		return lessChange(comparison.MovedDown[i], comparison.MovedDown[j], -1)
+	})
+	sort.Slice(comparison.Unchanged, func(i, j int) bool {
+		return comparison.Unchanged[i].CardBody < comparison.Unchanged[j].CardBody
+	})
+	sort.Strings(comparison.Added)
+	sort.Strings(comparison.Removed)
+	return comparison
+}
+
+func lessChange(a CardChange, b CardChange, direction int) bool {
+	if a.Change != b.Change {
+		return a.Change*direction > b.Change*direction
+	}
+	return a.CardBody < b.CardBody
+}
+
+type rankedCard struct {
+	column string
+	rank   int
+}
+
+func rankCards(board ValueSortBoard) map[string]rankedCard {
+	columns := append([]ValueSortColumn{}, board.Columns...)
+	sort.SliceStable(columns, func(i, j int) bool { return columns[i].Order < columns[j].Order })
+	ranked := map[string]rankedCard{}
+	for rank, col := range columns {
+		for _, card := range col.Cards {
+			ranked[card.Body] = rankedCard{column: col.Title, rank: rank}
+		}
+	}
+	return ranked
+}
@@ -0,0 +1,135 @@
+package valuesort
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type MockSnapshotModel struct {
+	MockBoardModel
+	snapshots map[string]ValueSortBoard
+}
+
+func (model MockSnapshotModel) CreateSnapshot(boardName string, name string, owner string) (Snapshot, error) {
+	board, err := model.Get(boardName)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{BoardName: boardName, Name: name, DateCreated: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC), Board: &board}, nil
+}
+func (model MockSnapshotModel) GetSnapshot(boardName string, name string) (Snapshot, error) {
+	board, ok := model.snapshots[boardName+"/"+name]
+	if !ok {
+		return Snapshot{}, errors.New("no rows in result set")
+	}
+	return Snapshot{BoardName: boardName, Name: name, Board: &board}, nil
+}
+
+func mockSnapshots() MockSnapshotModel {
+	model := MockSnapshotModel{MockBoardModel: mockBoards()}
+	model.snapshots = map[string]ValueSortBoard{
+		"some-board/january": {
+			Name: "some-board",
+			Columns: []ValueSortColumn{
+				{Title: "Unsorted", Order: 0, Cards: []ValueSortCard{}},
+				{Title: "Important", Order: 1, Cards: []ValueSortCard{{Body: "ACCEPTANCE"}}},
+			},
+		},
+	}
+	return model
+}
+
+func TestCreateSnapshotHandler(t *testing.T) {
+	model := mockSnapshots()
+	cases := []struct {
+		boardName    string
+		body         string
+		expectedCode int
+	}{
+		{"some-board", `{"name": "january"}`, 201},
+		{"some-board", `{"name": " "}`, 422},
+		{"missing-board", `{"name": "january"}`, 404},
+	}
+	for _, c := range cases {
+		rr := put(t, CreateSnapshotHandler(model), c.boardName, c.body)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s on %s but received %d", c.expectedCode, c.body, c.boardName, rr.Code)
+		}
+	}
+}
+
+func TestCompareBoardsHandler(t *testing.T) {
+	model := mockSnapshots()
+	cases := []struct {
+		query        string
+		expectedCode int
+	}{
+		{"?from=some-board&fromSnapshot=january&to=some-board", 200},
+		{"?from=some-board&fromSnapshot=february&to=some-board", 404},
+		{"?from=some-board&to=missing-board", 404},
+		{"?from=some-board", 422},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", "/api/v1/value-sort/compare"+c.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		CompareBoardsHandler(model).ServeHTTP(rr, mux.SetURLVars(req, nil))
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s but received %d", c.expectedCode, c.query, rr.Code)
+			continue
+		}
+		if rr.Code != 200 {
+			continue
+		}
+		var comparison Comparison
+		err = json.Unmarshal(rr.Body.Bytes(), &comparison)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(comparison.MovedDown) != 1 || comparison.MovedDown[0] != (CardChange{CardBody: "ACCEPTANCE", From: "Important", To: "Unsorted", Change: -1}) {
+			t.Errorf("expected ACCEPTANCE to have moved down but received %+v", comparison)
+		}
+	}
+}
+
+func TestCompareBoards(t *testing.T) {
+	cards := func(bodies ...string) []ValueSortCard {
+		result := []ValueSortCard{}
+		for _, body := range bodies {
+			result = append(result, ValueSortCard{Body: body})
+		}
+		return result
+	}
+	from := ValueSortBoard{Columns: []ValueSortColumn{
+		{Title: "Not Important", Order: 0, Cards: cards("ART", "BEAUTY")},
+		{Title: "Important", Order: 1, Cards: cards("CARING")},
+		{Title: "Most Important", Order: 2, Cards: cards("WEALTH", "DUTY")},
+	}}
+	// listed out of order, and with a column the first board doesn't have
+	to := ValueSortBoard{Columns: []ValueSortColumn{
+		{Title: "Essential", Order: 3, Cards: cards("ART")},
+		{Title: "Low", Order: 0, Cards: cards("WEALTH", "FAMILY")},
+		{Title: "Mid", Order: 1, Cards: cards("CARING", "BEAUTY")},
+		{Title: "High", Order: 2},
+	}}
+
+	comparison := CompareBoards(from, to)
+	got, _ := json.Marshal(comparison)
+	expected := `{"movedUp":[` +
+		`{"cardBody":"ART","from":"Not Important","to":"Essential","change":3},` +
+		`{"cardBody":"BEAUTY","from":"Not Important","to":"Mid","change":1}],` +
+		`"movedDown":[{"cardBody":"WEALTH","from":"Most Important","to":"Low","change":-2}],` +
+		`"unchanged":[{"cardBody":"CARING","from":"Important","to":"Mid","change":0}],` +
+		`"added":["FAMILY"],"removed":["DUTY"]}`
+	if string(got) != expected {
+		t.Errorf("expected %s but received %s", expected, got)
+	}
+}
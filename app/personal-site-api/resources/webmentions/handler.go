@@ -0,0 +1,100 @@
+package webmentions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+func ListWebmentionsHandler(model WebmentionDataAccessLayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		mentions, err := model.List(vars["articleURI"])
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "article not found", http.StatusNotFound)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem fetching webmentions", http.StatusInternalServerError)
+			}
+			return
+		}
+		jbytes, err := json.Marshal(mentions)
+		if err != nil {
+			http.Error(w, "internal error building response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(jbytes)
+	}
+}
+
+// The webmention endpoint. Takes a form encoded source and target, records the
+// mention against the target article and leaves the source to be checked in
+// the background, answering 202 straight away.
+func ReceiveWebmentionHandler(model WebmentionDataAccessLayer, verifier *Verifier, articleBaseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, "body must be form encoded", http.StatusBadRequest)
+			return
+		}
+		source := r.PostForm.Get("source")
+		target := r.PostForm.Get("target")
+		if !isHTTPURL(source) || !isHTTPURL(target) {
+			http.Error(w, "source and target must both be http(s) urls", http.StatusBadRequest)
+			return
+		}
+		if source == target {
+			http.Error(w, "source and target must be different", http.StatusBadRequest)
+			return
+		}
+		articleURI, ok := articleURIFromTarget(target, articleBaseURL)
+		if !ok {
+			http.Error(w, "target is not an article on this site", http.StatusBadRequest)
+			return
+		}
+
+		mention, err := model.Receive(articleURI, source, target)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				http.Error(w, "target is not an article on this site", http.StatusBadRequest)
+			} else {
+				fmt.Println(err.Error())
+				http.Error(w, "problem recording webmention", http.StatusInternalServerError)
+			}
+			return
+		}
+		if !verifier.Enqueue(mention) {
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "too many webmentions waiting to be checked, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// The article a target url points at, ignoring any query or fragment
+func articleURIFromTarget(target string, articleBaseURL string) (string, bool) {
+	if articleBaseURL == "" || !strings.HasPrefix(target, articleBaseURL) {
+		return "", false
+	}
+	uri := strings.TrimPrefix(target, articleBaseURL)
+	if idx := strings.IndexAny(uri, "?#"); idx >= 0 {
+		uri = uri[:idx]
+	}
+	uri = strings.TrimSuffix(uri, "/")
+	if uri == "" || strings.Contains(uri, "/") {
+		return "", false
+	}
+	return uri, true
+}
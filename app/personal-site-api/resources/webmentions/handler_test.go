@@ -0,0 +1,202 @@
+package webmentions
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testArticleBaseURL = "https://example.com/articles/"
+
+type MockWebmentionModel struct {
+	articles []string
+	mentions *[]Mention
+}
+
+func (model MockWebmentionModel) hasArticle(uri string) bool {
+	for _, article := range model.articles {
+		if article == uri {
+			return true
+		}
+	}
+	return false
+}
+func (model MockWebmentionModel) List(articleURI string) ([]Mention, error) {
+	if !model.hasArticle(articleURI) {
+		return nil, errors.New("no rows in result set")
+	}
+	mentions := []Mention{}
+	for _, m := range *model.mentions {
+		if m.Status == StatusVerified {
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions, nil
+}
+func (model MockWebmentionModel) Receive(articleURI string, source string, target string) (Mention, error) {
+	if !model.hasArticle(articleURI) {
+		return Mention{}, errors.New("no rows in result set")
+	}
+	mention := Mention{ID: len(*model.mentions) + 1, Source: source, Target: target, Status: StatusPending}
+	*model.mentions = append(*model.mentions, mention)
+	return mention, nil
+}
+func (model MockWebmentionModel) SetStatus(id int, status string) error {
+	for idx := range *model.mentions {
+		if (*model.mentions)[idx].ID == id {
+			(*model.mentions)[idx].Status = status
+			return nil
+		}
+	}
+	return errors.New("no rows in result set")
+}
+
+func newMockModel() MockWebmentionModel {
+	return MockWebmentionModel{articles: []string{"some-article-1"}, mentions: &[]Mention{}}
+}
+
+func TestReceiveWebmentionHandler(t *testing.T) {
+	cases := []struct {
+		source       string
+		target       string
+		expectedCode int
+	}{
+		{"https://blog.example.org/post", testArticleBaseURL + "some-article-1", 202},
+		{"https://blog.example.org/post", testArticleBaseURL + "some-article-1/#comments", 202},
+		{"https://blog.example.org/post", testArticleBaseURL + "some-article-2", 400},
+		{"https://blog.example.org/post", "https://elsewhere.example.com/articles/some-article-1", 400},
+		{"https://blog.example.org/post", testArticleBaseURL + "some-article-1/assets/x.png", 400},
+		{"ftp://blog.example.org/post", testArticleBaseURL + "some-article-1", 400},
+		{"", testArticleBaseURL + "some-article-1", 400},
+		{testArticleBaseURL + "some-article-1", testArticleBaseURL + "some-article-1", 400},
+	}
+	for _, c := range cases {
+		model := newMockModel()
+		verifier := NewVerifier(model)
+		form := url.Values{"source": {c.source}, "target": {c.target}}
+		req, err := http.NewRequest("POST", "/api/v1/webmention", strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rr := httptest.NewRecorder()
+		ReceiveWebmentionHandler(model, verifier, testArticleBaseURL).ServeHTTP(rr, req)
+		if rr.Code != c.expectedCode {
+			t.Errorf("expected status code %d for %s -> %s but received %d", c.expectedCode, c.source, c.target, rr.Code)
+		}
+		queued := len(verifier.queue) == 1
+		if queued != (c.expectedCode == 202) {
+			t.Errorf("expected queued to be %t for %s -> %s", c.expectedCode == 202, c.source, c.target)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	target := testArticleBaseURL + "some-article-1"
+	pages := map[string]struct {
+		contentType string
+		code        int
+		body        string
+	}{
+		"/links":       {"text/html; charset=utf-8", 200, `<p>Read <a class="u-in-reply-to" href="` + target + `">this</a></p>`},
+		"/escaped":     {"text/html", 200, `<a href='` + strings.Replace(target, "-", "&#45;", 1) + `'>this</a>`},
+		"/text-only":   {"text/html", 200, `<p>I read ` + target + ` today</p>`},
+		"/plain":       {"text/plain", 200, "see " + target},
+		"/no-link":     {"text/html", 200, `<a href="https://example.com/articles/other">other</a>`},
+		"/gone":        {"text/html", 410, ""},
+		"/not-found":   {"text/html", 404, ""},
+		"/unavailable": {"text/html", 503, ""},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := pages[r.URL.Path]
+		w.Header().Set("Content-Type", page.contentType)
+		w.WriteHeader(page.code)
+		w.Write([]byte(page.body))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		path           string
+		expectedStatus string
+		expectErr      bool
+	}{
+		{"/links", StatusVerified, false},
+		{"/escaped", StatusVerified, false},
+		{"/text-only", StatusInvalid, false},
+		{"/plain", StatusVerified, false},
+		{"/no-link", StatusInvalid, false},
+		{"/gone", StatusInvalid, false},
+		{"/not-found", StatusInvalid, false},
+		{"/unavailable", "", true},
+	}
+	verifier := NewVerifier(newMockModel())
+	verifier.Client = server.Client()
+	for _, c := range cases {
+		status, err := verifier.Verify(server.URL+c.path, target)
+		if (err != nil) != c.expectErr {
+			t.Errorf("expected error to be %t for %s but received %v", c.expectErr, c.path, err)
+		}
+		if status != c.expectedStatus {
+			t.Errorf("expected status '%s' for %s but received '%s'", c.expectedStatus, c.path, status)
+		}
+	}
+}
+
+func TestVerifierProcess(t *testing.T) {
+	target := testArticleBaseURL + "some-article-1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a href="` + target + `">a great read</a>`))
+	}))
+	defer server.Close()
+
+	model := newMockModel()
+	mention, _ := model.Receive("some-article-1", server.URL, target)
+	verifier := NewVerifier(model)
+	verifier.Client = server.Client()
+	verifier.Process(mention)
+
+	mentions, _ := model.List("some-article-1")
+	if len(mentions) != 1 || mentions[0].Source != server.URL {
+		t.Errorf("expected the mention to be verified but received %+v", *model.mentions)
+	}
+}
+
+func TestVerifyRefusesInternalAddresses(t *testing.T) {
+	target := testArticleBaseURL + "some-article-1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected %s not to be fetched", r.URL.Path)
+	}))
+	defer server.Close()
+
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	sources := []string{
+		server.URL + "/links",
+		"http://localhost:" + port + "/links",
+		"http://[::1]:" + port + "/links",
+		"http://0.0.0.0:" + port + "/links",
+	}
+	verifier := NewVerifier(newMockModel())
+	for _, source := range sources {
+		status, err := verifier.Verify(source, target)
+		if err != nil || status != StatusInvalid {
+			t.Errorf("expected %s to be refused as invalid but received '%s', %v", source, status, err)
+		}
+	}
+
+	for _, address := range []string{"10.0.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "100.64.0.1", "100.127.255.254", "224.0.0.251", "239.255.255.250", "ff02::1", "ff0e::1"} {
+		if isPublic(net.ParseIP(address)) {
+			t.Errorf("expected %s not to be public", address)
+		}
+	}
+	for _, address := range []string{"93.184.216.34", "100.63.255.255", "100.128.0.1", "2606:2800:220:1::1"} {
+		if !isPublic(net.ParseIP(address)) {
+			t.Errorf("expected %s to be public", address)
+		}
+	}
+}
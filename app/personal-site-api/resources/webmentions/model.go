@@ -0,0 +1,177 @@
+package webmentions
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusVerified = "verified"
+	StatusInvalid  = "invalid"
+)
+
+// Another page saying it links to one of our articles. Mentions stay pending
+// until the source has been fetched and the link found.
+type Mention struct {
+	ID           int        `json:"id"`
+	Source       string     `json:"source"`
+	Target       string     `json:"target"`
+	Status       string     `json:"status"`
+	DateCreated  time.Time  `json:"dateCreated"`
+	DateVerified *time.Time `json:"dateVerified"`
+}
+
+// An interface to refresent the Model (for mocking in test)
+type WebmentionDataAccessLayer interface {
+	List(articleURI string) (mentions []Mention, err error)
+	Receive(articleURI string, source string, target string) (mention Mention, err error)
+	SetStatus(id int, status string) (err error)
+}
+
+// What the sender needs to know about articles and the links it has notified
+type OutboxDataAccessLayer interface {
+	ArticleBody(articleURI string) (body string, err error)
+	SentTargets(articleURI string) (targets []string, err error)
+	RecordSent(articleURI string, targets []string) (err error)
+}
+
+// The Model with Database Implementation
+type WebmentionModel struct {
+	DB *pgx.Conn
+}
+
+// Verified mentions of the article, oldest first
+func (model *WebmentionModel) List(articleURI string) (mentions []Mention, err error) {
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return mentions, err
+	}
+
+	stmt := `
+		SELECT id, source, target, status, dt_created, dt_verified
+		FROM article_webmentions
+		WHERE article_id = $1 AND status = $2
+		ORDER BY dt_created, id;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, articleID, StatusVerified)
+	if err != nil {
+		return mentions, err
+	}
+	defer rows.Close()
+
+	mentions = []Mention{}
+	for rows.Next() {
+		var m Mention
+		err = rows.Scan(&m.ID, &m.Source, &m.Target, &m.Status, &m.DateCreated, &m.DateVerified)
+		if err != nil {
+			return mentions, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, rows.Err()
+}
+
+// Records the mention, or finds the one already recorded for the same source
+// and target, ready to be (re)verified. A repeat keeps its current status so
+// a verified mention stays listed while it's checked again.
+func (model *WebmentionModel) Receive(articleURI string, source string, target string) (mention Mention, err error) {
+	var articleID int
+	err = model.DB.QueryRow(context.Background(), "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return mention, err
+	}
+
+	mention = Mention{Source: source, Target: target}
+	stmt := `
+		INSERT INTO article_webmentions (article_id, source, target, status, dt_created)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (source, target)
+		DO
+			UPDATE SET article_id = $1
+		RETURNING id, status, dt_created, dt_verified
+	`
+	err = model.DB.QueryRow(context.Background(), stmt, articleID, source, target, StatusPending, time.Now()).Scan(
+		&mention.ID, &mention.Status, &mention.DateCreated, &mention.DateVerified,
+	)
+	if err != nil {
+		return mention, database.TranslateError(err)
+	}
+	return mention, nil
+}
+
+func (model *WebmentionModel) SetStatus(id int, status string) (err error) {
+	_, err = model.DB.Exec(
+		context.Background(),
+		"UPDATE article_webmentions SET status = $2, dt_verified = $3 WHERE id = $1;",
+		id, status, time.Now(),
+	)
+	return err
+}
+
+func (model *WebmentionModel) ArticleBody(articleURI string) (body string, err error) {
+	err = model.DB.QueryRow(context.Background(), "SELECT body_md FROM articles WHERE uri = $1;", articleURI).Scan(&body)
+	return body, err
+}
+
+func (model *WebmentionModel) SentTargets(articleURI string) (targets []string, err error) {
+	stmt := `
+		SELECT target
+		FROM article_webmention_sends
+		JOIN articles ON articles.id = article_webmention_sends.article_id
+		WHERE articles.uri = $1
+		ORDER BY target;
+	`
+	rows, err := model.DB.Query(context.Background(), stmt, articleURI)
+	if err != nil {
+		return targets, err
+	}
+	defer rows.Close()
+
+	targets = []string{}
+	for rows.Next() {
+		var target string
+		err = rows.Scan(&target)
+		if err != nil {
+			return targets, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// Replaces the article's sent targets with the links it has now
+func (model *WebmentionModel) RecordSent(articleURI string, targets []string) (err error) {
+	ctx := context.Background()
+	tx, err := model.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var articleID int
+	err = tx.QueryRow(ctx, "SELECT id FROM articles WHERE uri = $1;", articleURI).Scan(&articleID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM article_webmention_sends WHERE article_id = $1;", articleID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, target := range targets {
+		_, err = tx.Exec(
+			ctx,
+			"INSERT INTO article_webmention_sends (article_id, target, dt_sent) VALUES ($1, $2, $3);",
+			articleID, target, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
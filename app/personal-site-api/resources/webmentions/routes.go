@@ -0,0 +1,20 @@
+package webmentions
+
+import (
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+)
+
+var receiveLimiter = middleware.NewRateLimiter(30, 10*time.Minute)
+
+// The endpoint other sites send webmentions to
+func InitializeRoutes(router *mux.Router, model WebmentionDataAccessLayer, verifier *Verifier, articleBaseURL string) {
+	router.HandleFunc("", middleware.RateLimitMiddleware(receiveLimiter, ReceiveWebmentionHandler(model, verifier, articleBaseURL))).Methods("POST")
+}
+
+// Verified mentions, under an article
+func InitializeArticleRoutes(router *mux.Router, model WebmentionDataAccessLayer) {
+	router.HandleFunc("", ListWebmentionsHandler(model)).Methods("GET")
+}
@@ -0,0 +1,240 @@
+package webmentions
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`\]\(\s*<?(https?://[^)\s>]+)`)
+	autolinkPattern     = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	htmlLinkPattern     = regexp.MustCompile(`(?i)\bhref\s*=\s*["'](https?://[^"']+)["']`)
+
+	linkHeaderPattern = regexp.MustCompile(`<([^>]*)>([^,]*)`)
+	relParamPattern   = regexp.MustCompile(`(?i);\s*rel\s*=\s*(?:"([^"]*)"|([^\s;,]+))`)
+	linkTagPattern    = regexp.MustCompile(`(?is)<(?:link|a)\b[^>]*>`)
+	relAttrPattern    = regexp.MustCompile(`(?i)\brel\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	hrefAttrPattern   = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// Sends webmentions for every link in an article, in the background, retrying
+// endpoints that fail with a server error
+type Sender struct {
+	Client     *http.Client
+	Model      OutboxDataAccessLayer
+	ArticleURL func(uri string) string
+	Retries    int
+	Backoff    time.Duration
+
+	queue chan string
+}
+
+func NewSender(model OutboxDataAccessLayer, articleURL func(uri string) string) *Sender {
+	return &Sender{
+		Client:     newPublicClient(),
+		Model:      model,
+		ArticleURL: articleURL,
+		Retries:    3,
+		Backoff:    time.Second,
+		queue:      make(chan string, 100),
+	}
+}
+
+func (sender *Sender) Start() {
+	go func() {
+		for uri := range sender.queue {
+			err := sender.SendForArticle(uri)
+			if err != nil {
+				fmt.Printf("webmention: %s\n", err.Error())
+			}
+		}
+	}()
+}
+
+// Queues the article's links to be notified, dropping it when the queue is full
+func (sender *Sender) Enqueue(articleURI string) {
+	select {
+	case sender.queue <- articleURI:
+	default:
+		fmt.Printf("webmention: queue full, not sending for %s\n", articleURI)
+	}
+}
+
+// Notifies every page the article links to, plus any it linked to the last
+// time, so pages that were unlinked by an edit hear about it too
+func (sender *Sender) SendForArticle(articleURI string) error {
+	body, err := sender.Model.ArticleBody(articleURI)
+	if err != nil {
+		return err
+	}
+	previous, err := sender.Model.SentTargets(articleURI)
+	if err != nil {
+		return err
+	}
+
+	source := sender.ArticleURL(articleURI)
+	links := ExtractLinks(body)
+	failed := []string{}
+	for _, target := range union(links, previous) {
+		err = sender.Send(source, target)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", target, err.Error()))
+		}
+	}
+
+	err = sender.Model.RecordSent(articleURI, links)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("couldn't notify %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Discovers the target's endpoint and sends the mention. Targets without an
+// endpoint are skipped.
+func (sender *Sender) Send(source string, target string) error {
+	var endpoint string
+	err := sender.withRetries(func() (retry bool, err error) {
+		endpoint, retry, err = DiscoverEndpoint(sender.Client, target)
+		return retry, err
+	})
+	if err != nil || endpoint == "" {
+		return err
+	}
+
+	return sender.withRetries(func() (bool, error) {
+		form := url.Values{"source": {source}, "target": {target}}
+		resp, err := sender.Client.PostForm(endpoint, form)
+		if err != nil {
+			return !errors.Is(err, ErrForbiddenAddress), err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxFetchBytes))
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return false, nil
+		}
+		return retryable(resp.StatusCode), fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	})
+}
+
+// Tries again after a growing pause while the attempt says it's worth retrying
+func (sender *Sender) withRetries(attempt func() (retry bool, err error)) error {
+	wait := sender.Backoff
+	for tries := 0; ; tries++ {
+		retry, err := attempt()
+		if err == nil || !retry || tries >= sender.Retries {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func retryable(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusTooManyRequests
+}
+
+// Finds the target's webmention endpoint from its Link headers or, for HTML,
+// the first <link> or <a> with rel="webmention". An empty endpoint means the
+// target doesn't accept webmentions.
+func DiscoverEndpoint(client *http.Client, target string) (endpoint string, retry bool, err error) {
+	resp, err := client.Get(target)
+	if err != nil {
+		return "", !errors.Is(err, ErrForbiddenAddress), err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", retryable(resp.StatusCode), fmt.Errorf("target responded %d", resp.StatusCode)
+	}
+	// relative endpoints resolve against where any redirects ended up
+	base := resp.Request.URL
+
+	for _, header := range resp.Header.Values("Link") {
+		for _, link := range linkHeaderPattern.FindAllStringSubmatch(header, -1) {
+			for _, rel := range relParamPattern.FindAllStringSubmatch(link[2], -1) {
+				if hasRel(rel[1]+rel[2], "webmention") {
+					return resolve(base, link[1])
+				}
+			}
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", false, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
+	if err != nil {
+		return "", true, err
+	}
+	for _, tag := range linkTagPattern.FindAllString(string(body), -1) {
+		rel := relAttrPattern.FindStringSubmatch(tag)
+		href := hrefAttrPattern.FindStringSubmatch(tag)
+		if rel != nil && href != nil && hasRel(rel[1]+rel[2]+rel[3], "webmention") {
+			return resolve(base, html.UnescapeString(href[1]+href[2]+href[3]))
+		}
+	}
+	return "", false, nil
+}
+
+func resolve(base *url.URL, ref string) (string, bool, error) {
+	u, err := base.Parse(ref)
+	if err != nil {
+		return "", false, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false, errors.New("endpoint isn't an http(s) url")
+	}
+	return u.String(), false, nil
+}
+
+func hasRel(rels string, want string) bool {
+	for _, rel := range strings.Fields(strings.ToLower(rels)) {
+		if rel == want {
+			return true
+		}
+	}
+	return false
+}
+
+// The absolute http(s) links in a markdown body, each listed once
+func ExtractLinks(body string) []string {
+	links := []string{}
+	seen := map[string]bool{}
+	for _, pattern := range []*regexp.Regexp{markdownLinkPattern, autolinkPattern, htmlLinkPattern} {
+		for _, match := range pattern.FindAllStringSubmatchIndex(body, -1) {
+			link := html.UnescapeString(body[match[2]:match[3]])
+			if !seen[link] {
+				seen[link] = true
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
+func union(a []string, b []string) []string {
+	result := append([]string{}, a...)
+	seen := map[string]bool{}
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
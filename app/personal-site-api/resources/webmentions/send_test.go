@@ -0,0 +1,170 @@
+package webmentions
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type MockOutboxModel struct {
+	bodies map[string]string
+	sent   map[string][]string
+}
+
+func (model MockOutboxModel) ArticleBody(articleURI string) (string, error) {
+	body, ok := model.bodies[articleURI]
+	if !ok {
+		return "", errors.New("no rows in result set")
+	}
+	return body, nil
+}
+func (model MockOutboxModel) SentTargets(articleURI string) ([]string, error) {
+	return model.sent[articleURI], nil
+}
+func (model MockOutboxModel) RecordSent(articleURI string, targets []string) error {
+	model.sent[articleURI] = targets
+	return nil
+}
+
+// A site with a few pages advertising endpoints in different ways, and an
+// endpoint that fails a set number of times before accepting
+type mentionSite struct {
+	mu       sync.Mutex
+	failures int
+	received []string
+}
+
+func (site *mentionSite) server() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", `<https://example.com/other>; rel="me", </endpoint?via=header>; rel="webmention"`)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<link rel="webmention" href="/wrong">`))
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><link href="endpoint?via=html" rel="pingback webmention"></head></html>`))
+	})
+	mux.HandleFunc("/none", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<p>no endpoint here</p>`))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/html", http.StatusFound)
+	})
+	mux.HandleFunc("/endpoint", func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		defer site.mu.Unlock()
+		if site.failures > 0 {
+			site.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		r.ParseForm()
+		site.received = append(site.received, r.URL.Query().Get("via")+" "+r.PostForm.Get("source")+" "+r.PostForm.Get("target"))
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return httptest.NewServer(mux)
+}
+
+func testSender(model OutboxDataAccessLayer) *Sender {
+	sender := NewSender(model, func(uri string) string { return testArticleBaseURL + uri })
+	sender.Backoff = 0
+	// the test sites listen on loopback, which the real client refuses
+	sender.Client = &http.Client{}
+	return sender
+}
+
+func TestDiscoverEndpoint(t *testing.T) {
+	site := &mentionSite{}
+	server := site.server()
+	defer server.Close()
+
+	cases := map[string]string{
+		"/header": server.URL + "/endpoint?via=header",
+		"/html":   server.URL + "/endpoint?via=html",
+		"/moved":  server.URL + "/endpoint?via=html",
+		"/none":   "",
+	}
+	for path, expected := range cases {
+		endpoint, _, err := DiscoverEndpoint(server.Client(), server.URL+path)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", path, err.Error())
+		}
+		if endpoint != expected {
+			t.Errorf("expected endpoint '%s' for %s but received '%s'", expected, path, endpoint)
+		}
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	site := &mentionSite{failures: 2}
+	server := site.server()
+	defer server.Close()
+
+	sender := testSender(MockOutboxModel{})
+	err := sender.Send("https://example.com/articles/a", server.URL+"/html")
+	if err != nil {
+		t.Fatalf("expected the mention to be sent after retrying but received %s", err.Error())
+	}
+	if len(site.received) != 1 {
+		t.Errorf("expected 1 mention to be received but received %d", len(site.received))
+	}
+
+	site.failures = 10
+	err = sender.Send("https://example.com/articles/a", server.URL+"/html")
+	if err == nil {
+		t.Errorf("expected an error once retries ran out")
+	}
+	if expectedFailures := 10 - (sender.Retries + 1); site.failures != expectedFailures {
+		t.Errorf("expected %d attempts but there were %d", sender.Retries+1, 10-site.failures)
+	}
+}
+
+func TestSendForArticle(t *testing.T) {
+	site := &mentionSite{}
+	server := site.server()
+	defer server.Close()
+
+	model := MockOutboxModel{
+		bodies: map[string]string{
+			"some-article-1": "See [one](" + server.URL + "/header) and <" + server.URL + "/none>.\n\n" +
+				"Also [one again](" + server.URL + "/header) and a [relative link](/articles/other).",
+		},
+		sent: map[string][]string{
+			"some-article-1": {server.URL + "/html"},
+		},
+	}
+	err := testSender(model).SendForArticle("some-article-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := testArticleBaseURL + "some-article-1"
+	expectedReceived := []string{
+		"header " + source + " " + server.URL + "/header",
+		// no longer linked, but told so it can drop the mention
+		"html " + source + " " + server.URL + "/html",
+	}
+	if !reflect.DeepEqual(site.received, expectedReceived) {
+		t.Errorf("expected %v to be received but received %v", expectedReceived, site.received)
+	}
+	expectedSent := []string{server.URL + "/header", server.URL + "/none"}
+	if !reflect.DeepEqual(model.sent["some-article-1"], expectedSent) {
+		t.Errorf("expected sent targets %v but received %v", expectedSent, model.sent["some-article-1"])
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	body := "[a](https://a.example/x?y=1&amp;z=2) <https://b.example> " +
+		`<a href="http://c.example/">c</a> [rel](/local) [a again](https://a.example/x?y=1&amp;z=2)` +
+		"\n[mail](mailto:me@example.com)"
+	expected := []string{"https://a.example/x?y=1&z=2", "https://b.example", "http://c.example/"}
+	links := ExtractLinks(body)
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v but received %v", expected, links)
+	}
+}
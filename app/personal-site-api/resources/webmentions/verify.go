@@ -0,0 +1,112 @@
+package webmentions
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Don't read more than this much of a page looking for links
+const maxFetchBytes = 1 << 20
+
+var linkAttrPattern = regexp.MustCompile(`(?i)\b(?:href|src)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// Checks received mentions in the background, one at a time
+type Verifier struct {
+	Client *http.Client
+	Model  WebmentionDataAccessLayer
+
+	queue chan Mention
+}
+
+func NewVerifier(model WebmentionDataAccessLayer) *Verifier {
+	return &Verifier{
+		Client: newPublicClient(),
+		Model:  model,
+		queue:  make(chan Mention, 100),
+	}
+}
+
+func (verifier *Verifier) Start() {
+	go func() {
+		for mention := range verifier.queue {
+			verifier.Process(mention)
+		}
+	}()
+}
+
+// Queues the mention for verification, or returns false when the queue is full
+func (verifier *Verifier) Enqueue(mention Mention) bool {
+	select {
+	case verifier.queue <- mention:
+		return true
+	default:
+		return false
+	}
+}
+
+func (verifier *Verifier) Process(mention Mention) {
+	status, err := verifier.Verify(mention.Source, mention.Target)
+	if err != nil {
+		// leave it as it was, the sender will likely try again
+		fmt.Printf("webmention: couldn't fetch %s: %s\n", mention.Source, err.Error())
+		return
+	}
+	err = verifier.Model.SetStatus(mention.ID, status)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// Fetches the source and looks for a link to the target. A source that's gone
+// or no longer links to the target invalidates the mention.
+func (verifier *Verifier) Verify(source string, target string) (status string, err error) {
+	req, err := http.NewRequest("GET", source, nil)
+	if err != nil {
+		return StatusInvalid, nil
+	}
+	req.Header.Set("Accept", "text/html, */*;q=0.5")
+	resp, err := verifier.Client.Do(req)
+	if errors.Is(err, ErrForbiddenAddress) {
+		return StatusInvalid, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return "", fmt.Errorf("source responded %d", resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return StatusInvalid, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
+	if err != nil {
+		return "", err
+	}
+	if linksTo(string(body), resp.Header.Get("Content-Type"), target) {
+		return StatusVerified, nil
+	}
+	return StatusInvalid, nil
+}
+
+// HTML must link to the target from an attribute, anything else just has to mention it
+func linksTo(body string, contentType string, target string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return strings.Contains(body, target)
+	}
+	for _, match := range linkAttrPattern.FindAllStringSubmatch(body, -1) {
+		if html.UnescapeString(match[1]+match[2]+match[3]) == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// Returned by Get when nothing is stored under the key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Somewhere to keep uploaded files. Keys are slash separated paths such as
+// "articles/1/diagram.png"; anything S3 compatible could sit behind this too.
+type BlobStore interface {
+	Put(key string, r io.Reader) (err error)
+	Get(key string) (blob io.ReadCloser, err error)
+	Delete(key string) (err error)
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestLocalBlobStoreRoundTrip(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "articles/1/diagram.png"
+	err = store.Put(key, bytes.NewBufferString("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(key, bytes.NewBufferString("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(blob)
+	blob.Close()
+	if string(content) != "second" {
+		t.Errorf("expected blob content '%s' but received '%s'", "second", string(content))
+	}
+
+	err = store.Delete(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.Get(key)
+	if err != ErrBlobNotFound {
+		t.Errorf("expected ErrBlobNotFound but received %v", err)
+	}
+	if err = store.Delete(key); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed but received %v", err)
+	}
+}
+
+func TestLocalBlobStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "/", "../outside", "articles/../../outside"} {
+		if err := store.Put(key, bytes.NewBufferString("x")); err == nil {
+			t.Errorf("expected an error for key '%s'", key)
+		}
+	}
+}
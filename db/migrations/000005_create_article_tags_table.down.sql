@@ -0,0 +1 @@
+DROP TABLE article_tags;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE article_tags (
+    article_id INTEGER,
+    tag TEXT,
+    PRIMARY KEY (article_id, tag),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX article_tags_tag_idx ON article_tags (tag);
@@ -0,0 +1,4 @@
+DROP INDEX articles_search_vector_idx;
+
+ALTER TABLE articles
+DROP COLUMN search_vector;
\ No newline at end of file
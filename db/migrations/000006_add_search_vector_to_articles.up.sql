@@ -0,0 +1,8 @@
+ALTER TABLE articles
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', summary), 'B') ||
+    setweight(to_tsvector('english', body_md), 'C')
+) STORED;
+
+CREATE INDEX articles_search_vector_idx ON articles USING GIN (search_vector);
\ No newline at end of file
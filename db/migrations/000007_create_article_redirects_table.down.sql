@@ -0,0 +1 @@
+DROP TABLE article_redirects;
\ No newline at end of file
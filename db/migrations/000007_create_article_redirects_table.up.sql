@@ -0,0 +1,6 @@
+CREATE TABLE article_redirects (
+    old_uri TEXT PRIMARY KEY,
+    article_id INTEGER NOT NULL,
+    dt_created TIMESTAMP NOT NULL,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
\ No newline at end of file
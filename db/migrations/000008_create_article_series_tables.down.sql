@@ -0,0 +1,2 @@
+DROP TABLE article_series_members;
+DROP TABLE article_series;
\ No newline at end of file
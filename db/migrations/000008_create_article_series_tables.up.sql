@@ -0,0 +1,17 @@
+CREATE TABLE article_series (
+    id SERIAL PRIMARY KEY,
+    slug TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    dt_created TIMESTAMP NOT NULL,
+    dt_updated TIMESTAMP
+);
+
+CREATE TABLE article_series_members (
+    series_id INTEGER NOT NULL,
+    article_id INTEGER UNIQUE NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (series_id, position),
+    FOREIGN KEY (series_id) REFERENCES article_series(id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
\ No newline at end of file
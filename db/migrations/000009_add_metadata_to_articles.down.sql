@@ -0,0 +1,5 @@
+ALTER TABLE articles
+DROP COLUMN word_count,
+DROP COLUMN reading_minutes,
+DROP COLUMN outline,
+DROP COLUMN first_image;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE articles
+ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN reading_minutes INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN outline JSONB NOT NULL DEFAULT '[]',
+ADD COLUMN first_image TEXT;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE article_assets;
\ No newline at end of file
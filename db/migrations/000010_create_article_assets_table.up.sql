@@ -0,0 +1,11 @@
+CREATE TABLE article_assets (
+    article_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    dt_created TIMESTAMP NOT NULL,
+    dt_updated TIMESTAMP,
+    PRIMARY KEY (article_id, name),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
\ No newline at end of file
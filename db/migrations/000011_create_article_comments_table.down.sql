@@ -0,0 +1 @@
+DROP TABLE article_comments;
\ No newline at end of file
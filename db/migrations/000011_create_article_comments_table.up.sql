@@ -0,0 +1,14 @@
+CREATE TABLE article_comments (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL,
+    parent_id INTEGER,
+    author_name TEXT NOT NULL,
+    body_md TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    dt_created TIMESTAMP NOT NULL,
+    dt_moderated TIMESTAMP,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES article_comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX article_comments_article_status_idx ON article_comments (article_id, status);
\ No newline at end of file
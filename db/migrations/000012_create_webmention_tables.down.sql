@@ -0,0 +1,2 @@
+DROP TABLE article_webmention_sends;
+DROP TABLE article_webmentions;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE article_webmentions (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    target TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'verified', 'invalid')),
+    dt_created TIMESTAMP NOT NULL,
+    dt_verified TIMESTAMP,
+    UNIQUE (source, target),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE TABLE article_webmention_sends (
+    article_id INTEGER NOT NULL,
+    target TEXT NOT NULL,
+    dt_sent TIMESTAMP NOT NULL,
+    PRIMARY KEY (article_id, target),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
\ No newline at end of file
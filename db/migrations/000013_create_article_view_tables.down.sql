@@ -0,0 +1,3 @@
+DROP TABLE article_view_visitors;
+DROP TABLE article_view_referrers;
+DROP TABLE article_view_counts;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE article_view_counts (
+    article_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    views INTEGER NOT NULL,
+    PRIMARY KEY (article_id, day),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE TABLE article_view_referrers (
+    article_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    referrer TEXT NOT NULL,
+    views INTEGER NOT NULL,
+    PRIMARY KEY (article_id, day, referrer),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE TABLE article_view_visitors (
+    day DATE NOT NULL,
+    visitor_hash TEXT NOT NULL,
+    PRIMARY KEY (day, visitor_hash)
+);
+
+CREATE INDEX article_view_counts_day_idx ON article_view_counts (day);
+CREATE INDEX article_view_referrers_day_idx ON article_view_referrers (day);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE value_sort_cards DROP CONSTRAINT value_sort_cards_board_name_column_name_fkey;
+DROP TABLE value_sort_columns;
\ No newline at end of file
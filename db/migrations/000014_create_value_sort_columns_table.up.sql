@@ -0,0 +1,35 @@
+CREATE TABLE value_sort_columns (
+    board_name TEXT NOT NULL,
+    title TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    card_limit INTEGER,
+    PRIMARY KEY (board_name, title),
+    CHECK (card_limit IS NULL OR card_limit > 0)
+);
+
+-- existing boards get the columns that used to be hard-coded, plus any
+-- column their cards were already in, so nothing goes missing
+INSERT INTO value_sort_columns (board_name, title, position)
+SELECT boards.board_name, defaults.title, defaults.position - 1
+FROM (SELECT DISTINCT board_name FROM value_sort_cards) boards
+CROSS JOIN unnest(ARRAY[
+    'Unsorted',
+    'Not Important',
+    'Somewhat Important',
+    'Important',
+    'Very Important',
+    'Most Important'
+]) WITH ORDINALITY AS defaults (title, position);
+
+INSERT INTO value_sort_columns (board_name, title, position)
+SELECT cards.board_name, cards.column_name, 5 + row_number() OVER (PARTITION BY cards.board_name ORDER BY cards.column_name)
+FROM (SELECT DISTINCT board_name, column_name FROM value_sort_cards) cards
+WHERE NOT EXISTS (
+    SELECT 1 FROM value_sort_columns c
+    WHERE c.board_name = cards.board_name AND c.title = cards.column_name
+);
+
+ALTER TABLE value_sort_cards
+    ADD FOREIGN KEY (board_name, column_name)
+    REFERENCES value_sort_columns (board_name, title)
+    ON UPDATE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE value_sort_cards DROP COLUMN position;
\ No newline at end of file
@@ -0,0 +1,11 @@
+ALTER TABLE value_sort_cards ADD COLUMN position INTEGER;
+
+UPDATE value_sort_cards c
+SET position = ranked.position
+FROM (
+    SELECT board_name, card_body, row_number() OVER (PARTITION BY board_name, column_name ORDER BY card_body) - 1 AS position
+    FROM value_sort_cards
+) ranked
+WHERE c.board_name = ranked.board_name AND c.card_body = ranked.card_body;
+
+ALTER TABLE value_sort_cards ALTER COLUMN position SET NOT NULL;
\ No newline at end of file
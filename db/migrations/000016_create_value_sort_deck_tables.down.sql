@@ -0,0 +1,3 @@
+DROP TABLE value_sort_deck_card_text;
+DROP TABLE value_sort_deck_cards;
+DROP TABLE value_sort_decks;
\ No newline at end of file
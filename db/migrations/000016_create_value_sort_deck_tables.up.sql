@@ -0,0 +1,232 @@
+CREATE TABLE value_sort_decks (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    default_locale TEXT NOT NULL
+);
+
+CREATE TABLE value_sort_deck_cards (
+    deck_name TEXT NOT NULL,
+    card_key TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (deck_name, card_key),
+    FOREIGN KEY (deck_name) REFERENCES value_sort_decks(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE value_sort_deck_card_text (
+    deck_name TEXT NOT NULL,
+    card_key TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    body TEXT NOT NULL,
+    details TEXT NOT NULL,
+    PRIMARY KEY (deck_name, card_key, locale),
+    FOREIGN KEY (deck_name, card_key) REFERENCES value_sort_deck_cards(deck_name, card_key) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+INSERT INTO value_sort_decks (name, description, default_locale)
+VALUES ('default', 'The personal values card sort', 'en');
+
+INSERT INTO value_sort_deck_cards (deck_name, card_key, position)
+VALUES
+    ('default', 'acceptance', 0),
+    ('default', 'accuracy', 1),
+    ('default', 'achievement', 2),
+    ('default', 'adventure', 3),
+    ('default', 'art', 4),
+    ('default', 'attractiveness', 5),
+    ('default', 'authority', 6),
+    ('default', 'autonomy', 7),
+    ('default', 'beauty', 8),
+    ('default', 'belonging', 9),
+    ('default', 'caring', 10),
+    ('default', 'challenge', 11),
+    ('default', 'comfort', 12),
+    ('default', 'commitment', 13),
+    ('default', 'compassion', 14),
+    ('default', 'complexity', 15),
+    ('default', 'compromise', 16),
+    ('default', 'contribution', 17),
+    ('default', 'cooperation', 18),
+    ('default', 'courage', 19),
+    ('default', 'courtesy', 20),
+    ('default', 'creativity', 21),
+    ('default', 'curiosity', 22),
+    ('default', 'dependability', 23),
+    ('default', 'diligence', 24),
+    ('default', 'duty', 25),
+    ('default', 'ecology', 26),
+    ('default', 'excitement', 27),
+    ('default', 'faithfulness', 28),
+    ('default', 'fame', 29),
+    ('default', 'family', 30),
+    ('default', 'fitness', 31),
+    ('default', 'flexibility', 32),
+    ('default', 'forgiveness', 33),
+    ('default', 'freedom', 34),
+    ('default', 'friendship', 35),
+    ('default', 'fun', 36),
+    ('default', 'generosity', 37),
+    ('default', 'genuineness', 38),
+    ('default', 'gods-will', 39),
+    ('default', 'gratitude', 40),
+    ('default', 'growth', 41),
+    ('default', 'health', 42),
+    ('default', 'honesty', 43),
+    ('default', 'hope', 44),
+    ('default', 'humility', 45),
+    ('default', 'humor', 46),
+    ('default', 'imagination', 47),
+    ('default', 'independence', 48),
+    ('default', 'industry', 49),
+    ('default', 'inner-peace', 50),
+    ('default', 'integrity', 51),
+    ('default', 'intelligence', 52),
+    ('default', 'intimacy', 53),
+    ('default', 'justice', 54),
+    ('default', 'knowledge', 55),
+    ('default', 'leadership', 56),
+    ('default', 'leisure', 57),
+    ('default', 'loved', 58),
+    ('default', 'loving', 59),
+    ('default', 'mastery', 60),
+    ('default', 'mindfulness', 61),
+    ('default', 'moderation', 62),
+    ('default', 'monogamy', 63),
+    ('default', 'music', 64),
+    ('default', 'non-conformity', 65),
+    ('default', 'novelty', 66),
+    ('default', 'nurturance', 67),
+    ('default', 'openness', 68),
+    ('default', 'order', 69),
+    ('default', 'passion', 70),
+    ('default', 'patriotism', 71),
+    ('default', 'pleasure', 72),
+    ('default', 'popularity', 73),
+    ('default', 'power', 74),
+    ('default', 'practicality', 75),
+    ('default', 'protect', 76),
+    ('default', 'provide', 77),
+    ('default', 'purpose', 78),
+    ('default', 'rationality', 79),
+    ('default', 'realism', 80),
+    ('default', 'responsibility', 81),
+    ('default', 'risk', 82),
+    ('default', 'romance', 83),
+    ('default', 'safety', 84),
+    ('default', 'self-acceptance', 85),
+    ('default', 'self-control', 86),
+    ('default', 'self-esteem', 87),
+    ('default', 'self-knowledge', 88),
+    ('default', 'service', 89),
+    ('default', 'sexuality', 90),
+    ('default', 'simplicity', 91),
+    ('default', 'solitude', 92),
+    ('default', 'spirituality', 93),
+    ('default', 'stability', 94),
+    ('default', 'tolerance', 95),
+    ('default', 'tradition', 96),
+    ('default', 'virtue', 97),
+    ('default', 'wealth', 98),
+    ('default', 'world', 99);
+
+INSERT INTO value_sort_deck_card_text (deck_name, card_key, locale, body, details)
+VALUES
+    ('default', 'acceptance', 'en', 'ACCEPTANCE', 'to be accepted as I am'),
+    ('default', 'accuracy', 'en', 'ACCURACY', 'to be correct in my opinions and beliefs'),
+    ('default', 'achievement', 'en', 'ACHIEVEMENT', 'to have important accomplishments'),
+    ('default', 'adventure', 'en', 'ADVENTURE', 'to have new and exciting experiences'),
+    ('default', 'art', 'en', 'ART', 'to appreciate or express myself in art'),
+    ('default', 'attractiveness', 'en', 'ATTRACTIVENESS', 'to be physically attractive'),
+    ('default', 'authority', 'en', 'AUTHORITY', 'to be in charge of others'),
+    ('default', 'autonomy', 'en', 'AUTONOMY', 'to be self-determined and independent'),
+    ('default', 'beauty', 'en', 'BEAUTY', 'to appreciate beauty around me'),
+    ('default', 'belonging', 'en', 'BELONGING', 'to have a sense of belonging, being part of'),
+    ('default', 'caring', 'en', 'CARING', 'to take care of others'),
+    ('default', 'challenge', 'en', 'CHALLENGE', 'to take on difficult tasks and problems'),
+    ('default', 'comfort', 'en', 'COMFORT', 'to have a pleasant and comfortable life'),
+    ('default', 'commitment', 'en', 'COMMITMENT', 'to make enduring, meaningful commitments'),
+    ('default', 'compassion', 'en', 'COMPASSION', 'to feel and act on concern for others'),
+    ('default', 'complexity', 'en', 'COMPLEXITY', 'to embrace the intricacies of life'),
+    ('default', 'compromise', 'en', 'COMPROMISE', 'to be willing to give and take in reaching agreements'),
+    ('default', 'contribution', 'en', 'CONTRIBUTION', 'to make a lasting contribution in the world'),
+    ('default', 'cooperation', 'en', 'COOPERATION', 'to work collaboratively with others'),
+    ('default', 'courage', 'en', 'COURAGE', 'to be brave and strong in the face of adversity'),
+    ('default', 'courtesy', 'en', 'COURTESY', 'to be considerate and polite toward others'),
+    ('default', 'creativity', 'en', 'CREATIVITY', 'to create new things or ideas'),
+    ('default', 'curiosity', 'en', 'CURIOSITY', 'to seek out, experience, and learn new things'),
+    ('default', 'dependability', 'en', 'DEPENDABILITY', 'to be reliable and trustworthy'),
+    ('default', 'diligence', 'en', 'DILIGENCE', 'to be thorough and conscientious in whatever I do'),
+    ('default', 'duty', 'en', 'DUTY', 'to carry out my duties and obligations'),
+    ('default', 'ecology', 'en', 'ECOLOGY', 'to live in harmony with the environment'),
+    ('default', 'excitement', 'en', 'EXCITEMENT', 'to have a life full of thrills and stimulation'),
+    ('default', 'faithfulness', 'en', 'FAITHFULNESS', 'to be loyal and true in relationships'),
+    ('default', 'fame', 'en', 'FAME', 'to be known and recognized'),
+    ('default', 'family', 'en', 'FAMILY', 'to have a happy, loving family'),
+    ('default', 'fitness', 'en', 'FITNESS', 'to be physically fit and strong'),
+    ('default', 'flexibility', 'en', 'FLEXIBILITY', 'to adjust to new circumstances easily to be forgiving of others'),
+    ('default', 'forgiveness', 'en', 'FORGIVENESS', 'to be forgiving of others'),
+    ('default', 'freedom', 'en', 'FREEDOM', 'to be free from undue restrictions and limitations'),
+    ('default', 'friendship', 'en', 'FRIENDSHIP', 'to have close, supportive friends'),
+    ('default', 'fun', 'en', 'FUN', 'to play and have fun'),
+    ('default', 'generosity', 'en', 'GENEROSITY', 'to give what I have to others'),
+    ('default', 'genuineness', 'en', 'GENUINENESS', 'to act in a manner that is true'),
+    ('default', 'gods-will', 'en', 'GOD"S WILL', 'to seek and obey the will of God'),
+    ('default', 'gratitude', 'en', 'GRATITUDE', 'to be thankful and appreciative'),
+    ('default', 'growth', 'en', 'GROWTH', 'to keep changing and growing'),
+    ('default', 'health', 'en', 'HEALTH', 'to be physically well and health'),
+    ('default', 'honesty', 'en', 'HONESTY', 'to be honest and truthful'),
+    ('default', 'hope', 'en', 'HOPE', 'to maintain a positive and optimistic outlook'),
+    ('default', 'humility', 'en', 'HUMILITY', 'to be modest and unassuming'),
+    ('default', 'humor', 'en', 'HUMOR', 'to see the humorous side of myself'),
+    ('default', 'imagination', 'en', 'IMAGINATION', 'to have dreams and see possibilities'),
+    ('default', 'independence', 'en', 'INDEPENDENCE', 'to be free from depending on others'),
+    ('default', 'industry', 'en', 'INDUSTRY', 'to work hard and well at my life tasks'),
+    ('default', 'inner-peace', 'en', 'INNER PEACE', 'to experience personal peace'),
+    ('default', 'integrity', 'en', 'INTEGRITY', 'to live my daily life in a way that is consistent with my values'),
+    ('default', 'intelligence', 'en', 'INTELLIGENCE', 'to keep my mind sharp and active'),
+    ('default', 'intimacy', 'en', 'INTIMACY', 'to share my innermost experiences with others'),
+    ('default', 'justice', 'en', 'JUSTICE', 'to promote fair and equal treatment for all'),
+    ('default', 'knowledge', 'en', 'KNOWLEDGE', 'to learn and contribute valuable knowledge'),
+    ('default', 'leadership', 'en', 'LEADERSHIP', 'to inspire and guide others'),
+    ('default', 'leisure', 'en', 'LEISURE', 'to take time to relax and enjoy'),
+    ('default', 'loved', 'en', 'LOVED', 'to be loved by those close to me'),
+    ('default', 'loving', 'en', 'LOVING', 'to give love to others'),
+    ('default', 'mastery', 'en', 'MASTERY', 'to be competent in my everyday activities'),
+    ('default', 'mindfulness', 'en', 'MINDFULNESS', 'to live conscious and mindful of the present moment'),
+    ('default', 'moderation', 'en', 'MODERATION', 'to avoid excesses and find a middle ground'),
+    ('default', 'monogamy', 'en', 'MONOGAMY', 'to have one close, loving relationship'),
+    ('default', 'music', 'en', 'MUSIC', 'to enjoy or express myself in music'),
+    ('default', 'non-conformity', 'en', 'NON-CONFORMITY', 'to question and challenge authority and norms'),
+    ('default', 'novelty', 'en', 'NOVELTY', 'to have a life full of change and variety'),
+    ('default', 'nurturance', 'en', 'NURTURANCE', 'to encourage and support others'),
+    ('default', 'openness', 'en', 'OPENNESS', 'to be open to new experiences, ideas, and options'),
+    ('default', 'order', 'en', 'ORDER', 'to have a life that is well and organized'),
+    ('default', 'passion', 'en', 'PASSION', 'to have deep feelings about ideas, activities, or people'),
+    ('default', 'patriotism', 'en', 'PATRIOTISM', 'to love, serve, and protect my country'),
+    ('default', 'pleasure', 'en', 'PLEASURE', 'to feel good'),
+    ('default', 'popularity', 'en', 'POPULARITY', 'to be well-liked by many people'),
+    ('default', 'power', 'en', 'POWER', 'to have control over others'),
+    ('default', 'practicality', 'en', 'PRACTICALITY', 'to focus on what is practical, prudent, and sensible'),
+    ('default', 'protect', 'en', 'PROTECT', 'to protect and keep safe those I love'),
+    ('default', 'provide', 'en', 'PROVIDE', 'to provide for and take care of my family'),
+    ('default', 'purpose', 'en', 'PURPOSE', 'to have meaning and direction in my life'),
+    ('default', 'rationality', 'en', 'RATIONALITY', 'to be guided by reason, logic, and evidence'),
+    ('default', 'realism', 'en', 'REALISM', 'to see and act realistically and practically'),
+    ('default', 'responsibility', 'en', 'RESPONSIBILITY', 'to make and carry out responsible decisions'),
+    ('default', 'risk', 'en', 'RISK', 'to take risks and chances'),
+    ('default', 'romance', 'en', 'ROMANCE', 'to have intense, exciting love in my life'),
+    ('default', 'safety', 'en', 'SAFETY', 'to be safe and secure'),
+    ('default', 'self-acceptance', 'en', 'SELF-ACCEPTANCE', 'to accept myself as I am'),
+    ('default', 'self-control', 'en', 'SELF-CONTROL', 'to be disciplined in my own actions'),
+    ('default', 'self-esteem', 'en', 'SELF-ESTEEM', 'to feel good about myself'),
+    ('default', 'self-knowledge', 'en', 'SELF-KNOWLEDGE', 'to have a deep and honest understanding of myself'),
+    ('default', 'service', 'en', 'SERVICE', 'to be helpful and of service to others'),
+    ('default', 'sexuality', 'en', 'SEXUALITY', 'to have an active and satisfying sex life'),
+    ('default', 'simplicity', 'en', 'SIMPLICITY', 'to live life simply, with minimal needs'),
+    ('default', 'solitude', 'en', 'SOLITUDE', 'to have time and space where be apart from others'),
+    ('default', 'spirituality', 'en', 'SPIRITUALITY', 'to grow and mature spiritually'),
+    ('default', 'stability', 'en', 'STABILITY', 'to have a life that stays fairly consistent'),
+    ('default', 'tolerance', 'en', 'TOLERANCE', 'to accept and respect those who differ from me'),
+    ('default', 'tradition', 'en', 'TRADITION', 'to follow respected patterns of the past'),
+    ('default', 'virtue', 'en', 'VIRTUE', 'to live a morally pure and excellent life'),
+    ('default', 'wealth', 'en', 'WEALTH', 'to have plenty of money'),
+    ('default', 'world', 'en', 'WORLD', 'to work to promote peace in the world');
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE value_sort_columns DROP CONSTRAINT value_sort_columns_board_name_fkey;
+DROP TABLE value_sort_boards;
\ No newline at end of file
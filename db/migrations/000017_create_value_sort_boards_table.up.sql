@@ -0,0 +1,15 @@
+CREATE TABLE value_sort_boards (
+    name TEXT PRIMARY KEY,
+    deck_name TEXT,
+    locale TEXT,
+    date_created TIMESTAMPTZ NOT NULL DEFAULT now(),
+    date_modified TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO value_sort_boards (name)
+SELECT DISTINCT board_name FROM value_sort_columns;
+
+ALTER TABLE value_sort_columns
+    ADD FOREIGN KEY (board_name)
+    REFERENCES value_sort_boards (name)
+    ON UPDATE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE value_sort_moves;
\ No newline at end of file
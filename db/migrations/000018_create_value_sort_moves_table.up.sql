@@ -0,0 +1,18 @@
+CREATE TABLE value_sort_moves (
+    id SERIAL PRIMARY KEY,
+    board_name TEXT NOT NULL,
+    card_body TEXT NOT NULL,
+    card_details TEXT,
+    from_column TEXT,
+    to_column TEXT,
+    position INTEGER,
+    moved_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE
+);
+
+CREATE INDEX value_sort_moves_board_idx ON value_sort_moves (board_name, moved_at);
+
+-- boards sorted before moves were recorded start their history as they are now
+INSERT INTO value_sort_moves (board_name, card_body, card_details, to_column, position)
+SELECT board_name, card_body, card_details, column_name, position
+FROM value_sort_cards;
\ No newline at end of file
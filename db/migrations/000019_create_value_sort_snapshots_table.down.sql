@@ -0,0 +1 @@
+DROP TABLE value_sort_snapshots;
\ No newline at end of file
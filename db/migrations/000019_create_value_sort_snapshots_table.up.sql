@@ -0,0 +1,8 @@
+CREATE TABLE value_sort_snapshots (
+    board_name TEXT NOT NULL,
+    name TEXT NOT NULL,
+    state JSONB NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (board_name, name),
+    FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE
+);
\ No newline at end of file
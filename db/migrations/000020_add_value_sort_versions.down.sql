@@ -0,0 +1,2 @@
+ALTER TABLE value_sort_cards DROP COLUMN version;
+ALTER TABLE value_sort_boards DROP COLUMN version;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE value_sort_boards ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE value_sort_cards ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE value_sort_round_columns;
+DROP TABLE value_sort_rounds;
+ALTER TABLE value_sort_boards DROP COLUMN current_round;
\ No newline at end of file
@@ -0,0 +1,25 @@
+ALTER TABLE value_sort_boards ADD COLUMN current_round INTEGER NOT NULL DEFAULT 0;
+
+-- a board with rounds is sorted in guided mode, one round at a time
+CREATE TABLE value_sort_rounds (
+    board_name TEXT NOT NULL,
+    number INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    PRIMARY KEY (board_name, number),
+    FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE
+);
+
+-- column names are kept in step with renames by the service, like the move
+-- history, so boards only cascade here one way
+CREATE TABLE value_sort_round_columns (
+    board_name TEXT NOT NULL,
+    round_number INTEGER NOT NULL,
+    column_name TEXT NOT NULL,
+    capacity INTEGER,
+    required INTEGER,
+    PRIMARY KEY (board_name, round_number, column_name),
+    FOREIGN KEY (board_name, round_number) REFERENCES value_sort_rounds(board_name, number)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CHECK (capacity IS NULL OR capacity >= 0),
+    CHECK (required IS NULL OR required >= 0)
+);
\ No newline at end of file
@@ -0,0 +1,24 @@
+ALTER TABLE value_sort_rounds
+    DROP CONSTRAINT value_sort_rounds_board_name_fkey,
+    ADD CONSTRAINT value_sort_rounds_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE;
+
+ALTER TABLE value_sort_snapshots
+    DROP CONSTRAINT value_sort_snapshots_board_name_fkey,
+    ADD CONSTRAINT value_sort_snapshots_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE;
+
+ALTER TABLE value_sort_moves
+    DROP CONSTRAINT value_sort_moves_board_name_fkey,
+    ADD CONSTRAINT value_sort_moves_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE;
+
+ALTER TABLE value_sort_cards
+    DROP CONSTRAINT value_sort_cards_board_name_column_name_fkey,
+    ADD CONSTRAINT value_sort_cards_board_name_column_name_fkey FOREIGN KEY (board_name, column_name)
+    REFERENCES value_sort_columns (board_name, title)
+    ON UPDATE CASCADE;
+
+ALTER TABLE value_sort_columns
+    DROP CONSTRAINT value_sort_columns_board_name_fkey,
+    ADD CONSTRAINT value_sort_columns_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE;
+
+DROP INDEX value_sort_boards_owner_idx;
+ALTER TABLE value_sort_boards DROP COLUMN owner;
\ No newline at end of file
@@ -0,0 +1,26 @@
+ALTER TABLE value_sort_boards ADD COLUMN owner TEXT;
+
+CREATE INDEX value_sort_boards_owner_idx ON value_sort_boards (owner, date_modified);
+
+-- deleting a board takes everything on it with it
+ALTER TABLE value_sort_columns
+    DROP CONSTRAINT value_sort_columns_board_name_fkey,
+    ADD CONSTRAINT value_sort_columns_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE value_sort_cards
+    DROP CONSTRAINT value_sort_cards_board_name_column_name_fkey,
+    ADD CONSTRAINT value_sort_cards_board_name_column_name_fkey FOREIGN KEY (board_name, column_name)
+    REFERENCES value_sort_columns (board_name, title)
+    ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE value_sort_moves
+    DROP CONSTRAINT value_sort_moves_board_name_fkey,
+    ADD CONSTRAINT value_sort_moves_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE value_sort_snapshots
+    DROP CONSTRAINT value_sort_snapshots_board_name_fkey,
+    ADD CONSTRAINT value_sort_snapshots_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE value_sort_rounds
+    DROP CONSTRAINT value_sort_rounds_board_name_fkey,
+    ADD CONSTRAINT value_sort_rounds_board_name_fkey FOREIGN KEY (board_name) REFERENCES value_sort_boards(name) ON UPDATE CASCADE ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE articles DROP COLUMN dt_series_changed;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- when the article's series, or its place in one, last changed, so
+-- conditional requests notice the series block changing
+ALTER TABLE articles ADD COLUMN dt_series_changed TIMESTAMP;
+
+UPDATE articles a
+SET dt_series_changed = COALESCE(s.dt_updated, s.dt_created)
+FROM article_series_members m
+JOIN article_series s ON s.id = m.series_id
+WHERE m.article_id = a.id;
\ No newline at end of file
@@ -3,9 +3,11 @@ module github.com/jdwoo/personal-site-go-server
 go 1.17
 
 require (
+	github.com/go-pdf/fpdf v0.6.0
 	github.com/gorilla/mux v1.8.0
 	github.com/jackc/pgx/v4 v4.14.1
 	github.com/spf13/viper v1.16.0
+	golang.org/x/image v0.18.0
 	gopkg.in/go-playground/validator.v9 v9.31.0
 )
 
@@ -32,7 +34,7 @@ require (
 	github.com/subosito/gotenv v1.4.2 // indirect
 	golang.org/x/crypto v0.9.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
-	golang.org/x/text v0.9.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
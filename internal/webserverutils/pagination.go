@@ -0,0 +1,150 @@
+package webserverutils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Describes what a list endpoint accepts for paging, sorting and field selection
+type PageOptions struct {
+	DefaultLimit int
+	MaxLimit     int
+	DefaultSort  string   // e.g. "-created" for newest first
+	SortFields   []string // names accepted by the sort param
+	Fields       []string // json field names accepted by the fields param
+}
+
+// Parsed list parameters from the query string
+type PageParams struct {
+	Limit  int
+	Cursor *Cursor
+	Sort   string
+	Desc   bool
+	Fields []string
+}
+
+// The position after the last item of a page: the sort key value and id as a tie breaker
+type Cursor struct {
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (c Cursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, NewRequestError("malformed cursor")
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return c, NewRequestError("malformed cursor")
+	}
+	return c, nil
+}
+
+// Reads limit, cursor, sort and fields from the request, e.g. ?limit=10&sort=-title&fields=uri,title
+func ParsePageParams(r *http.Request, opts PageOptions) (params PageParams, err error) {
+	query := r.URL.Query()
+
+	params.Limit = opts.DefaultLimit
+	if limit := query.Get("limit"); limit != "" {
+		params.Limit, err = strconv.Atoi(limit)
+		if err != nil || params.Limit < 1 {
+			return params, NewRequestError("limit must be a positive integer")
+		}
+	}
+	if opts.MaxLimit > 0 && params.Limit > opts.MaxLimit {
+		params.Limit = opts.MaxLimit
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return params, err
+		}
+		params.Cursor = &c
+	}
+
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = opts.DefaultSort
+	}
+	params.Desc = strings.HasPrefix(sort, "-")
+	params.Sort = strings.TrimPrefix(sort, "-")
+	if !contains(opts.SortFields, params.Sort) {
+		return params, NewRequestError(fmt.Sprintf("cannot sort by '%s'", params.Sort))
+	}
+
+	if fields := query.Get("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if !contains(opts.Fields, field) {
+				return params, NewRequestError(fmt.Sprintf("unknown field '%s'", field))
+			}
+			params.Fields = append(params.Fields, field)
+		}
+	}
+
+	return params, nil
+}
+
+// Whether the field should be included in the response
+func (params PageParams) HasField(field string) bool {
+	return len(params.Fields) == 0 || contains(params.Fields, field)
+}
+
+// Adds the total count and, when there is another page, a Link header pointing at it
+func SetPageHeaders(w http.ResponseWriter, r *http.Request, total int, nextCursor string) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if nextCursor == "" {
+		return
+	}
+	next := *r.URL
+	query := next.Query()
+	query.Set("cursor", nextCursor)
+	next.RawQuery = query.Encode()
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+}
+
+// Trims each item of a json encodable slice down to the requested fields
+func SelectFields(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var all []map[string]json.RawMessage
+	err = json.Unmarshal(b, &all)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]map[string]json.RawMessage, len(all))
+	for idx, item := range all {
+		selected[idx] = map[string]json.RawMessage{}
+		for _, field := range fields {
+			if value, ok := item[field]; ok {
+				selected[idx][field] = value
+			}
+		}
+	}
+	return selected, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package webserverutils
+
+import (
+	"net/http"
+	"testing"
+)
+
+var testPageOptions = PageOptions{
+	DefaultLimit: 20,
+	MaxLimit:     50,
+	DefaultSort:  "-created",
+	SortFields:   []string{"created", "title"},
+	Fields:       []string{"id", "title"},
+}
+
+func TestParsePageParamsDefaults(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/things", nil)
+	params, err := ParsePageParams(req, testPageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Limit != 20 || params.Sort != "created" || !params.Desc || params.Cursor != nil {
+		t.Errorf("unexpected default params %+v", params)
+	}
+}
+
+func TestParsePageParamsCursorRoundTrip(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Value: "Some title", ID: 7})
+	req, _ := http.NewRequest("GET", "/things?limit=500&sort=title&fields=title&cursor="+cursor, nil)
+	params, err := ParsePageParams(req, testPageOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Limit != 50 {
+		t.Errorf("expected limit to be capped at %d but received %d", 50, params.Limit)
+	}
+	if params.Cursor == nil || params.Cursor.Value != "Some title" || params.Cursor.ID != 7 {
+		t.Errorf("unexpected cursor %+v", params.Cursor)
+	}
+	if params.HasField("id") {
+		t.Errorf("expected id to be excluded from fields %v", params.Fields)
+	}
+}
+
+func TestParsePageParamsErrors(t *testing.T) {
+	for _, query := range []string{"limit=0", "sort=body", "fields=body", "cursor=not-a-cursor"} {
+		req, _ := http.NewRequest("GET", "/things?"+query, nil)
+		_, err := ParsePageParams(req, testPageOptions)
+		if err == nil {
+			t.Errorf("expected an error for '%s'", query)
+		}
+	}
+}
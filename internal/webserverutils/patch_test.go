@@ -0,0 +1,71 @@
+package webserverutils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func assertJSONEqual(t *testing.T, expected string, actual []byte) {
+	t.Helper()
+	var e, a interface{}
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(actual, &a); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %s but received %s", expected, string(actual))
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	doc := `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`
+	patch := `{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`
+	result, err := MergePatch([]byte(doc), []byte(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-123-456-7890"}`
+	assertJSONEqual(t, expected, result)
+}
+
+func TestJSONPatch(t *testing.T) {
+	doc := `{"id": 1, "title": "Some title", "tags": ["go", "testing"], "nested": {"a": 1}}`
+	patch := `[
+		{"op": "test", "path": "/id", "value": 1},
+		{"op": "replace", "path": "/title", "value": "A better title"},
+		{"op": "add", "path": "/tags/-", "value": "fuzzing"},
+		{"op": "add", "path": "/tags/0", "value": "benchmarks"},
+		{"op": "remove", "path": "/tags/2"},
+		{"op": "copy", "from": "/nested/a", "path": "/nested/b"},
+		{"op": "move", "from": "/nested/a", "path": "/c~1d"}
+	]`
+	result, err := JSONPatch([]byte(doc), []byte(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"id": 1, "title": "A better title", "tags": ["benchmarks", "go", "fuzzing"], "nested": {"b": 1}, "c/d": 1}`
+	assertJSONEqual(t, expected, result)
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	doc := `{"id": 1, "tags": ["go"], "nested": {"a": 1}}`
+	patches := []string{
+		`[{"op": "test", "path": "/id", "value": 2}]`,
+		`[{"op": "remove", "path": "/missing"}]`,
+		`[{"op": "add", "path": "/tags/5", "value": "x"}]`,
+		`[{"op": "replace", "path": "title", "value": "x"}]`,
+		`[{"op": "move", "from": "/nested", "path": "/nested/a/b"}]`,
+		`[{"op": "frobnicate", "path": "/id"}]`,
+		`[{"op": "add", "path": "/title"}]`,
+		`{"op": "add", "path": "/title", "value": "x"}`,
+	}
+	for _, patch := range patches {
+		_, err := JSONPatch([]byte(doc), []byte(patch))
+		if err == nil {
+			t.Errorf("expected an error for %s", patch)
+		}
+	}
+}
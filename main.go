@@ -12,9 +12,15 @@ import (
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/cfg"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/database"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/middleware"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/analytics"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/articles"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/assets"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/comments"
 	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/learning"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/sitemap"
 	valuesort "github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/value_sort"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/resources/webmentions"
+	"github.com/jdwoo/personal-site-go-server/app/personal-site-api/storage"
 )
 
 func rootHandler(w http.ResponseWriter, r *http.Request) {
@@ -42,19 +48,50 @@ func metaHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
-func initializeRoutes(db *pgx.Conn) *mux.Router {
+func initializeRoutes(db *pgx.Conn, config *cfg.Config, store storage.BlobStore) *mux.Router {
 	r := mux.NewRouter()
 	r.Use(middleware.LoggingMiddleware)
 	r.HandleFunc("/", rootHandler)
 	r.HandleFunc("/meta", metaHandler)
 	apiV1 := r.PathPrefix("/api/v1").Subrouter()
 	apiV1.Use(middleware.CorsMiddleware)
-	articles.InitializeRoutes(apiV1.PathPrefix("/articles").Subrouter(), &articles.ArticleModel{DB: db})
-	valuesort.InitializeRoutes(apiV1.PathPrefix("/value-sort").Subrouter(), &valuesort.ValueSortBoardModel{DB: db})
+	mentionModel := &webmentions.WebmentionModel{DB: db}
+	// the webmention workers run alongside requests, and a connection can't be
+	// shared between goroutines, so each gets one of its own
+	verifier := webmentions.NewVerifier(&webmentions.WebmentionModel{DB: connect(config)})
+	verifier.Start()
+	articleModel := &articles.ArticleModel{DB: db, Site: config.Site}
+	// outgoing webmentions need to know the public address of our articles
+	if config.Site.ArticleBaseURL != "" {
+		sender := webmentions.NewSender(&webmentions.WebmentionModel{DB: connect(config)}, config.Site.ArticleURL)
+		sender.Start()
+		articleModel.Published = sender.Enqueue
+	}
+
+	articlesRouter := apiV1.PathPrefix("/articles").Subrouter()
+	analytics.InitializeRoutes(articlesRouter, &analytics.AnalyticsModel{DB: db})
+	articles.InitializeRoutes(articlesRouter, articleModel, config.Site)
+	sitemap.InitializeRoutes(r, articleModel, config.Site)
+	assets.InitializeRoutes(articlesRouter.PathPrefix("/{articleURI}/assets").Subrouter(), &assets.AssetModel{DB: db, Store: store}, config.Storage.MaxUploadBytes)
+	comments.InitializeRoutes(articlesRouter.PathPrefix("/{articleURI}/comments").Subrouter(), &comments.CommentModel{DB: db})
+	webmentions.InitializeArticleRoutes(articlesRouter.PathPrefix("/{articleURI}/webmentions").Subrouter(), mentionModel)
+	webmentions.InitializeRoutes(apiV1.PathPrefix("/webmention").Subrouter(), mentionModel, verifier, config.Site.ArticleBaseURL)
+	// live boards listen for changes on a connection of their own
+	boardHub := valuesort.NewBoardHub(func() (*pgx.Conn, error) { return database.InitalizeDatabase(config) })
+	boardHub.Start()
+	valuesort.InitializeRoutes(apiV1.PathPrefix("/value-sort").Subrouter(), &valuesort.ValueSortBoardModel{DB: db}, &valuesort.DeckModel{DB: db}, boardHub)
 	learning.InitializeRoutes(apiV1.PathPrefix("/lessons").Subrouter(), &learning.LessonModel{DB: db})
 	return r
 }
 
+func connect(config *cfg.Config) *pgx.Conn {
+	db, err := database.InitalizeDatabase(config)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
 func main() {
 	config := cfg.Load()
 
@@ -63,5 +100,18 @@ func main() {
 		panic(err)
 	}
 	defer database.TeardownDatabase(db)
-	log.Fatal(http.ListenAndServe(":8080", initializeRoutes(db)))
+
+	// articles from before word counts and outlines were stored get them now
+	backfill := &articles.ArticleModel{DB: db, Site: config.Site}
+	if count, err := backfill.BackfillMetadata(); err != nil {
+		log.Printf("could not backfill article metadata: %v", err)
+	} else if count > 0 {
+		log.Printf("backfilled metadata for %d articles", count)
+	}
+
+	store, err := storage.NewLocalBlobStore(config.Storage.Path)
+	if err != nil {
+		panic(err)
+	}
+	log.Fatal(http.ListenAndServe(":8080", initializeRoutes(db, config, store)))
 }